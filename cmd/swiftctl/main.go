@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/arnavsurve/swiftctl/internal/cli"
+	"github.com/arnavsurve/swiftctl/internal/process"
 )
 
 var version = "dev"
@@ -21,7 +22,14 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
+		for {
+			sig := <-sigChan
+			// While lldb runs, Ctrl+C interrupts the app being debugged.
+			if sig == os.Interrupt && process.InForeground() {
+				continue
+			}
+			break
+		}
 		fmt.Fprintln(os.Stderr, "\nShutting down...")
 		cancel()
 
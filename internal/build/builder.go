@@ -3,9 +3,12 @@ package build
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/arnavsurve/swiftctl/internal/device"
@@ -27,6 +30,9 @@ type Config struct {
 	Destination   string
 	DerivedData   string
 	ExtraArgs     []string
+
+	// Output, when set, receives every line of build output as it arrives.
+	Output func(line string)
 }
 
 type EventType int
@@ -43,7 +49,17 @@ const (
 )
 
 type Event struct {
-	Type    EventType
+	Type     EventType
+	Message  string
+	File     string
+	Line     int
+	Column   int
+	Category string // e.g. "Swift Compiler Error", "Link Error"; set from xcresult
+	Notes    []Note
+}
+
+// Note is a supplementary message attached to a diagnostic.
+type Note struct {
 	Message string
 	File    string
 	Line    int
@@ -56,17 +72,25 @@ type Result struct {
 	Duration    time.Duration
 	Warnings    []Event
 	Errors      []Event
+
+	// From build settings; empty when they couldn't be read.
+	BundleID       string
+	ExecutablePath string
 }
 
 type Builder struct {
 	project *project.ProjectInfo
 	runner  *process.Runner
+
+	mu       sync.Mutex
+	settings map[string]*Settings
 }
 
-func NewBuilder(proj *project.ProjectInfo) *Builder {
+func NewBuilder(proj *project.ProjectInfo, opts ...process.Option) *Builder {
 	return &Builder{
-		project: proj,
-		runner:  process.NewRunner(),
+		project:  proj,
+		runner:   process.NewRunner(opts...),
+		settings: make(map[string]*Settings),
 	}
 }
 
@@ -79,10 +103,20 @@ func (b *Builder) Build(ctx context.Context, cfg Config, events chan<- Event) (*
 	startTime := time.Now()
 	result := &Result{}
 
+	bundlePath, cleanup, err := b.resultBundle()
+	if err != nil {
+		return nil, fmt.Errorf("result bundle: %w", err)
+	}
+	defer cleanup()
+
 	args := b.buildArgs(cfg)
+	args = append(args, "-resultBundlePath", bundlePath)
 
 	outChan, errChan := b.runner.Run(ctx, "xcodebuild", args)
+	// The streaming parser drives live progress; the final diagnostics come
+	// from the result bundle when it can be read.
 	parser := &outputParser{events: events, result: result}
+	var runErr error
 
 	for {
 		select {
@@ -93,6 +127,9 @@ func (b *Builder) Build(ctx context.Context, cfg Config, events chan<- Event) (*
 			if !ok {
 				outChan = nil
 			} else {
+				if cfg.Output != nil {
+					cfg.Output(line.Content)
+				}
 				parser.parseLine(line.Content)
 			}
 
@@ -100,9 +137,7 @@ func (b *Builder) Build(ctx context.Context, cfg Config, events chan<- Event) (*
 			if !ok {
 				errChan = nil
 			} else if err != nil {
-				result.Success = false
-				result.Duration = time.Since(startTime)
-				return result, fmt.Errorf("build failed: %w", err)
+				runErr = err
 			}
 		}
 
@@ -111,6 +146,30 @@ func (b *Builder) Build(ctx context.Context, cfg Config, events chan<- Event) (*
 		}
 	}
 
+	if b.runner.DryRun() {
+		// Nothing ran, so there is no success marker or bundle to parse.
+		result.Success = true
+	} else {
+		// Falls back to the streamed diagnostics and success marker.
+		b.applyResultBundle(ctx, bundlePath, result)
+	}
+
+	if runErr != nil {
+		result.Duration = time.Since(startTime)
+		result.Success = false
+		return result, fmt.Errorf("build failed: %w", runErr)
+	}
+
+	if result.Success {
+		if settings, err := b.Settings(ctx, cfg); err == nil {
+			result.ProductPath = settings.ProductPath()
+			result.BundleID = settings.BundleID
+			if settings.ExecutablePath != "" {
+				result.ExecutablePath = filepath.Join(settings.TargetBuildDir, settings.ExecutablePath)
+			}
+		}
+	}
+
 	result.Duration = time.Since(startTime)
 	return result, nil
 }
@@ -123,6 +182,9 @@ func (b *Builder) buildSPM(ctx context.Context, cfg Config, events chan<- Event)
 	if cfg.Configuration == ConfigRelease {
 		args = append(args, "-c", "release")
 	}
+	if cfg.Scheme != "" {
+		args = append(args, "--product", cfg.Scheme)
+	}
 
 	outChan, errChan := b.runner.Run(ctx, "swift", args)
 
@@ -135,17 +197,22 @@ func (b *Builder) buildSPM(ctx context.Context, cfg Config, events chan<- Event)
 			if !ok {
 				outChan = nil
 			} else {
+				if cfg.Output != nil {
+					cfg.Output(line.Content)
+				}
 				// Parse swift build output for errors
 				if strings.Contains(line.Content, "error:") {
+					ev := spmDiagnostic(EventError, line.Content)
 					if events != nil {
-						events <- Event{Type: EventError, Message: line.Content}
+						events <- ev
 					}
-					result.Errors = append(result.Errors, Event{Message: line.Content})
+					result.Errors = append(result.Errors, ev)
 				} else if strings.Contains(line.Content, "warning:") {
+					ev := spmDiagnostic(EventWarning, line.Content)
 					if events != nil {
-						events <- Event{Type: EventWarning, Message: line.Content}
+						events <- ev
 					}
-					result.Warnings = append(result.Warnings, Event{Message: line.Content})
+					result.Warnings = append(result.Warnings, ev)
 				} else if strings.Contains(line.Content, "Build complete!") {
 					result.Success = true
 					if events != nil {
@@ -174,11 +241,54 @@ func (b *Builder) buildSPM(ctx context.Context, cfg Config, events chan<- Event)
 		result.Success = true
 	}
 
+	if result.Success && cfg.Scheme != "" {
+		// Only executable products end up as a file named after the product.
+		if binPath, err := b.BinPath(ctx, cfg); err == nil {
+			exe := filepath.Join(binPath, cfg.Scheme)
+			if info, err := os.Stat(exe); err == nil && !info.IsDir() {
+				result.ProductPath = exe
+				result.ExecutablePath = exe
+			}
+		}
+	}
+
 	result.Duration = time.Since(startTime)
 	return result, nil
 }
 
+// BinPath returns the directory swift build puts products in.
+func (b *Builder) BinPath(ctx context.Context, cfg Config) (string, error) {
+	args := []string{"build", "--show-bin-path"}
+	if cfg.Configuration == ConfigRelease {
+		args = append(args, "-c", "release")
+	}
+
+	output, err := b.runner.RunSilent(ctx, "swift", args)
+	if err != nil {
+		return "", fmt.Errorf("swift build --show-bin-path: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// spmDiagnostic extracts the location from a swift build diagnostic line
+// when it has one, keeping the raw line as the message otherwise.
+func spmDiagnostic(t EventType, line string) Event {
+	matches := diagnosticPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if matches == nil {
+		return Event{Type: t, Message: line}
+	}
+
+	lineNum, _ := strconv.Atoi(matches[2])
+	col, _ := strconv.Atoi(matches[3])
+	return Event{Type: t, File: matches[1], Line: lineNum, Column: col, Message: matches[5]}
+}
+
 func (b *Builder) Clean(ctx context.Context, cfg Config) error {
+	if b.project.Type == project.ProjectTypeSPM {
+		_, err := b.runner.RunSilent(ctx, "swift", []string{"package", "clean"})
+		return err
+	}
+
 	args := b.buildArgs(cfg)
 	args = append(args, "clean")
 
@@ -0,0 +1,104 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// Settings is the subset of xcodebuild build settings swiftctl needs to
+// locate a built product.
+type Settings struct {
+	Target          string
+	TargetBuildDir  string
+	FullProductName string
+	BundleID        string
+	ExecutablePath  string // relative to TargetBuildDir
+}
+
+// ProductPath is the absolute path of the built product, e.g. the .app.
+func (s *Settings) ProductPath() string {
+	return filepath.Join(s.TargetBuildDir, s.FullProductName)
+}
+
+func settingsKey(cfg Config) string {
+	return strings.Join([]string{cfg.Scheme, string(cfg.Configuration), cfg.Destination, string(cfg.Platform), cfg.DerivedData}, "\x00")
+}
+
+// Settings returns the build settings of the scheme's main product. They are
+// cached per scheme, configuration and destination, so repeated builds in
+// watch mode only query xcodebuild once.
+func (b *Builder) Settings(ctx context.Context, cfg Config) (*Settings, error) {
+	key := settingsKey(cfg)
+
+	b.mu.Lock()
+	cached, ok := b.settings[key]
+	b.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	args := append(b.buildArgs(cfg), "-showBuildSettings", "-json")
+	output, err := b.runner.RunSilent(ctx, "xcodebuild", args)
+	if err != nil {
+		return nil, fmt.Errorf("showBuildSettings: %w", err)
+	}
+
+	scheme := cfg.Scheme
+	if scheme == "" && len(b.project.Schemes) > 0 {
+		scheme = b.project.Schemes[0]
+	}
+	settings, err := parseSettings(output, scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.settings[key] = settings
+	b.mu.Unlock()
+
+	return settings, nil
+}
+
+// parseSettings picks the scheme's application target from the
+// -showBuildSettings -json output, which lists every target the scheme
+// builds (including test bundles and frameworks).
+func parseSettings(data []byte, scheme string) (*Settings, error) {
+	targets := gjson.ParseBytes(data).Array()
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no build settings in xcodebuild output")
+	}
+
+	best := targets[0]
+	bestScore := -1
+	for _, t := range targets {
+		s := t.Get("buildSettings")
+		score := 0
+		if s.Get("WRAPPER_EXTENSION").String() == "app" {
+			score += 2
+		}
+		if t.Get("target").String() == scheme {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+
+	s := best.Get("buildSettings")
+	settings := &Settings{
+		Target:          best.Get("target").String(),
+		TargetBuildDir:  s.Get("TARGET_BUILD_DIR").String(),
+		FullProductName: s.Get("FULL_PRODUCT_NAME").String(),
+		BundleID:        s.Get("PRODUCT_BUNDLE_IDENTIFIER").String(),
+		ExecutablePath:  s.Get("EXECUTABLE_PATH").String(),
+	}
+	if settings.TargetBuildDir == "" || settings.FullProductName == "" {
+		return nil, fmt.Errorf("build settings for %s have no product path", settings.Target)
+	}
+
+	return settings, nil
+}
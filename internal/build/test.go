@@ -0,0 +1,344 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/project"
+)
+
+// TestConfig selects what to test. Only and Skip take xcodebuild test
+// identifiers (Target, Target/Class or Target/Class/method).
+type TestConfig struct {
+	Config
+	Only []string
+	Skip []string
+}
+
+type TestEventType int
+
+const (
+	TestStarted TestEventType = iota
+	TestPassed
+	TestFailed
+	TestSkipped
+)
+
+type TestEvent struct {
+	Type     TestEventType
+	Suite    string
+	Name     string
+	Duration time.Duration
+	Message  string // first failure message, for TestFailed
+	File     string
+	Line     int
+}
+
+type TestStatus string
+
+const (
+	StatusPassed  TestStatus = "passed"
+	StatusFailed  TestStatus = "failed"
+	StatusSkipped TestStatus = "skipped"
+)
+
+type TestCase struct {
+	Suite    string
+	Name     string
+	Status   TestStatus
+	Duration time.Duration
+	Failures []TestFailure
+}
+
+type TestFailure struct {
+	Message string
+	File    string
+	Line    int
+}
+
+type TestResult struct {
+	Success  bool
+	Duration time.Duration
+	Cases    []TestCase
+	Build    *Result // diagnostics from compiling the test bundle
+}
+
+// Counts tallies cases by status.
+func (r *TestResult) Counts() (passed, failed, skipped int) {
+	for _, c := range r.Cases {
+		switch c.Status {
+		case StatusPassed:
+			passed++
+		case StatusFailed:
+			failed++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Test builds and runs the project's tests, streaming per-test events to the
+// channel (can be nil). A non-nil result is returned whenever tests ran;
+// check Success for the outcome.
+func (b *Builder) Test(ctx context.Context, cfg TestConfig, events chan<- TestEvent) (*TestResult, error) {
+	startTime := time.Now()
+	result := &TestResult{Build: &Result{}}
+	parser := &testParser{events: events, result: result, running: make(map[string]*pendingTest)}
+
+	var name, bundlePath string
+	var args []string
+
+	if b.project.Type == project.ProjectTypeSPM {
+		name = "swift"
+		args = b.spmTestArgs(cfg)
+	} else {
+		var cleanup func()
+		var err error
+		bundlePath, cleanup, err = b.resultBundle()
+		if err != nil {
+			return nil, fmt.Errorf("result bundle: %w", err)
+		}
+		defer cleanup()
+
+		name = "xcodebuild"
+		args = b.buildArgs(cfg.Config)
+		args = append(args, "-resultBundlePath", bundlePath, "test")
+		for _, id := range cfg.Only {
+			args = append(args, "-only-testing:"+id)
+		}
+		for _, id := range cfg.Skip {
+			args = append(args, "-skip-testing:"+id)
+		}
+	}
+
+	buildParser := &outputParser{result: result.Build}
+	var testsStarted time.Time
+	outChan, errChan := b.runner.Run(ctx, name, args)
+	runErr, err := consume(ctx, outChan, errChan, func(line string) {
+		if parser.parseLine(line) {
+			if testsStarted.IsZero() {
+				testsStarted = time.Now()
+			}
+		} else {
+			buildParser.parseLine(line)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fromBundle := bundlePath != "" && !b.runner.DryRun() && b.applyResultBundle(ctx, bundlePath, result.Build)
+
+	result.Duration = time.Since(startTime)
+	// The build is over once the first test reports; if none did, it took
+	// the whole run.
+	result.Build.Duration = result.Duration
+	if !testsStarted.IsZero() {
+		result.Build.Duration = testsStarted.Sub(startTime)
+	}
+	if !fromBundle {
+		result.Build.Success = len(result.Build.Errors) == 0
+	}
+	_, failed, _ := result.Counts()
+	result.Success = runErr == nil && failed == 0
+
+	if runErr != nil && len(result.Cases) == 0 && len(result.Build.Errors) == 0 && !b.runner.DryRun() {
+		return result, fmt.Errorf("test failed: %w", runErr)
+	}
+
+	return result, nil
+}
+
+func (b *Builder) spmTestArgs(cfg TestConfig) []string {
+	args := []string{"test"}
+	if cfg.Configuration == ConfigRelease {
+		args = append(args, "-c", "release")
+	}
+	for _, id := range cfg.Only {
+		args = append(args, "--filter", spmTestFilter(id))
+	}
+	for _, id := range cfg.Skip {
+		args = append(args, "--skip", spmTestFilter(id))
+	}
+	return args
+}
+
+// spmTestFilter converts Target/Class/method to swift test's
+// Target.Class/method regex form.
+func spmTestFilter(id string) string {
+	parts := strings.SplitN(id, "/", 3)
+	switch len(parts) {
+	case 3:
+		return regexp.QuoteMeta(parts[0]+"."+parts[1]) + "/" + regexp.QuoteMeta(parts[2]) + "$"
+	case 2:
+		return regexp.QuoteMeta(parts[0] + "." + parts[1] + "/")
+	default:
+		return regexp.QuoteMeta(id)
+	}
+}
+
+// consume feeds every output line to fn until the command exits, returning
+// the command's exit error separately from context cancellation.
+func consume(ctx context.Context, outChan <-chan process.OutputLine, errChan <-chan error, fn func(string)) (runErr, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case line, ok := <-outChan:
+			if !ok {
+				outChan = nil
+			} else {
+				fn(line.Content)
+			}
+
+		case e, ok := <-errChan:
+			if !ok {
+				errChan = nil
+			} else if e != nil {
+				runErr = e
+			}
+		}
+
+		if outChan == nil && errChan == nil {
+			return runErr, nil
+		}
+	}
+}
+
+type pendingTest struct {
+	started  time.Time
+	failures []TestFailure
+}
+
+type testParser struct {
+	events  chan<- TestEvent
+	result  *TestResult
+	running map[string]*pendingTest
+}
+
+var (
+	// Test Case '-[Module.Class testMethod]' passed (0.002 seconds).
+	// Test Case 'Class.testMethod' started at 2024-01-01 10:00:00.000
+	xctestCasePattern = regexp.MustCompile(`^Test Case '(?:-\[)?(.+?)[ .]([^ .\]]+)\]?' (started|passed|failed|skipped)(?: \((\d+(?:\.\d+)?) seconds\))?`)
+	// Test case 'Class.testMethod()' passed on 'Clone 1 of iPhone 15 - App (123)' (0.002 seconds)
+	xctestParallelPattern = regexp.MustCompile(`^Test case '(.+?)\.([^.]+?)(?:\(\))?' (passed|failed|skipped) on '.+?' \((\d+(?:\.\d+)?) seconds\)`)
+	// /path/File.swift:12: error: -[Module.Class testMethod] : XCTAssertTrue failed
+	xctestFailurePattern = regexp.MustCompile(`^(.+?\.\w+):(\d+): error: (?:-\[)?(.+?)[ .]([^ .\]]+)\]? : (.+)$`)
+
+	// Swift Testing: ◇ Test foo() started. / ✔ Test foo() passed after 0.001 seconds.
+	stStartedPattern = regexp.MustCompile(`^◇ Test (.+?) started\.`)
+	stPassedPattern  = regexp.MustCompile(`^✔ Test (.+?) passed after (\d+(?:\.\d+)?) seconds`)
+	stFailedPattern  = regexp.MustCompile(`^✘ Test (.+?) failed after (\d+(?:\.\d+)?) seconds`)
+	stIssuePattern   = regexp.MustCompile(`^✘ Test (.+?) recorded an issue at (.+?):(\d+):\d+: (.+)$`)
+	stSkippedPattern = regexp.MustCompile(`^➜ Test (.+?) skipped`)
+)
+
+// parseLine handles test lifecycle lines and reports whether it consumed the line.
+func (p *testParser) parseLine(line string) bool {
+	line = strings.TrimSpace(line)
+
+	if m := xctestFailurePattern.FindStringSubmatch(line); m != nil {
+		lineNum, _ := strconv.Atoi(m[2])
+		p.recordFailure(m[3], m[4], TestFailure{Message: m[5], File: m[1], Line: lineNum})
+		return true
+	}
+
+	if m := xctestCasePattern.FindStringSubmatch(line); m != nil {
+		p.transition(m[1], m[2], m[3], m[4])
+		return true
+	}
+
+	if m := xctestParallelPattern.FindStringSubmatch(line); m != nil {
+		p.transition(m[1], m[2], m[3], m[4])
+		return true
+	}
+
+	if m := stIssuePattern.FindStringSubmatch(line); m != nil {
+		lineNum, _ := strconv.Atoi(m[3])
+		p.recordFailure("", m[1], TestFailure{Message: m[4], File: m[2], Line: lineNum})
+		return true
+	}
+
+	if m := stStartedPattern.FindStringSubmatch(line); m != nil {
+		p.transition("", m[1], "started", "")
+		return true
+	}
+	if m := stPassedPattern.FindStringSubmatch(line); m != nil {
+		p.transition("", m[1], "passed", m[2])
+		return true
+	}
+	if m := stFailedPattern.FindStringSubmatch(line); m != nil {
+		p.transition("", m[1], "failed", m[2])
+		return true
+	}
+	if m := stSkippedPattern.FindStringSubmatch(line); m != nil {
+		p.transition("", m[1], "skipped", "")
+		return true
+	}
+
+	return false
+}
+
+func (p *testParser) pending(suite, name string) *pendingTest {
+	key := suite + "/" + name
+	pt, ok := p.running[key]
+	if !ok {
+		pt = &pendingTest{started: time.Now()}
+		p.running[key] = pt
+	}
+	return pt
+}
+
+func (p *testParser) recordFailure(suite, name string, f TestFailure) {
+	pt := p.pending(suite, name)
+	pt.failures = append(pt.failures, f)
+}
+
+func (p *testParser) transition(suite, name, state, seconds string) {
+	if state == "started" {
+		p.running[suite+"/"+name] = &pendingTest{started: time.Now()}
+		p.emit(TestEvent{Type: TestStarted, Suite: suite, Name: name})
+		return
+	}
+
+	pt := p.pending(suite, name)
+	delete(p.running, suite+"/"+name)
+
+	var duration time.Duration
+	if secs, err := strconv.ParseFloat(seconds, 64); err == nil {
+		duration = time.Duration(secs * float64(time.Second))
+	} else if state != "skipped" {
+		duration = time.Since(pt.started)
+	}
+
+	tc := TestCase{Suite: suite, Name: name, Duration: duration, Failures: pt.failures}
+	ev := TestEvent{Suite: suite, Name: name, Duration: duration}
+
+	switch state {
+	case "passed":
+		tc.Status, ev.Type = StatusPassed, TestPassed
+	case "failed":
+		tc.Status, ev.Type = StatusFailed, TestFailed
+		if len(pt.failures) > 0 {
+			ev.Message, ev.File, ev.Line = pt.failures[0].Message, pt.failures[0].File, pt.failures[0].Line
+		}
+	case "skipped":
+		tc.Status, ev.Type = StatusSkipped, TestSkipped
+	}
+
+	p.result.Cases = append(p.result.Cases, tc)
+	p.emit(ev)
+}
+
+func (p *testParser) emit(ev TestEvent) {
+	if p.events != nil {
+		p.events <- ev
+	}
+}
@@ -0,0 +1,57 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/project"
+)
+
+func TestTestBuildDuration(t *testing.T) {
+	tests := []struct {
+		name      string
+		resp      process.FakeResponse
+		wholeRun  bool
+		wantCases int
+	}{
+		{
+			name: "tests ran",
+			resp: process.FakeResponse{Stdout: "Compiling MyApp Model.swift\n" +
+				"Test Case '-[MyAppTests.LoginTests testLogin]' started.\n" +
+				"Test Case '-[MyAppTests.LoginTests testLogin]' passed (0.012 seconds).\n"},
+			wantCases: 1,
+		},
+		{
+			name: "build failed",
+			resp: process.FakeResponse{
+				Stdout:   "/src/Model.swift:3:5: error: cannot find 'x' in scope\n",
+				ExitCode: 1,
+			},
+			wholeRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := process.NewFakeExecutor().On("swift test", tt.resp)
+			b := NewBuilder(&project.ProjectInfo{Type: project.ProjectTypeSPM}, process.WithExecutor(fake))
+
+			result, err := b.Test(context.Background(), TestConfig{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Cases) != tt.wantCases {
+				t.Fatalf("got %d cases, want %d", len(result.Cases), tt.wantCases)
+			}
+
+			build, total := result.Build.Duration, result.Duration
+			if tt.wholeRun && build != total {
+				t.Errorf("build took %v, want the whole run (%v)", build, total)
+			}
+			if !tt.wholeRun && (build <= 0 || build >= total) {
+				t.Errorf("build took %v, want between 0 and %v", build, total)
+			}
+		})
+	}
+}
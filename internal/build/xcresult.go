@@ -0,0 +1,165 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// resultBundle returns where xcodebuild should write its .xcresult and a
+// func removing it once parsed. Dry runs get a placeholder path and nothing
+// is created.
+func (b *Builder) resultBundle() (string, func(), error) {
+	if b.runner.DryRun() {
+		return filepath.Join(os.TempDir(), "swiftctl-xcresult", "Build.xcresult"), func() {}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "swiftctl-xcresult-")
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Join(dir, "Build.xcresult"), func() { os.RemoveAll(dir) }, nil
+}
+
+// applyResultBundle replaces result's diagnostics and outcome with the
+// bundle's, which are authoritative, and reports whether the bundle could be
+// read. When it can't, the streamed ones stand.
+func (b *Builder) applyResultBundle(ctx context.Context, path string, result *Result) bool {
+	errs, warnings, status, err := b.readResultBundle(ctx, path)
+	if err != nil {
+		return false
+	}
+	result.Errors = errs
+	result.Warnings = warnings
+	if status != "" {
+		result.Success = status == "succeeded"
+	} else {
+		result.Success = len(errs) == 0
+	}
+	return true
+}
+
+// readResultBundle loads the authoritative diagnostics and the build status
+// ("succeeded", "failed", ...) from an .xcresult bundle.
+func (b *Builder) readResultBundle(ctx context.Context, path string) (errs, warnings []Event, status string, err error) {
+	args := []string{"xcresulttool", "get", "--format", "json", "--path", path}
+
+	output, err := b.runner.RunSilent(ctx, "xcrun", args)
+	if err != nil {
+		// Xcode 16 moved the object graph API behind --legacy.
+		args = append(args, "--legacy")
+		output, err = b.runner.RunSilent(ctx, "xcrun", args)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("xcresulttool: %w", err)
+		}
+	}
+
+	errs, warnings = parseIssueSummaries(output)
+	status = parseBuildStatus(output)
+
+	// Notes are only recorded in the build log, referenced from the action.
+	if logID := gjson.GetBytes(output, "actions._values.0.buildResult.logRef.id._value").String(); logID != "" {
+		logOutput, err := b.runner.RunSilent(ctx, "xcrun", append(args, "--id", logID))
+		if err == nil {
+			notes := parseLogNotes(logOutput)
+			attachNotes(errs, notes)
+			attachNotes(warnings, notes)
+		}
+	}
+
+	return errs, warnings, status, nil
+}
+
+// parseBuildStatus reads the status of the first action's build, empty
+// when the bundle doesn't record one.
+func parseBuildStatus(data []byte) string {
+	return gjson.GetBytes(data, "actions._values.0.buildResult.status._value").String()
+}
+
+// parseIssueSummaries reads the root ActionsInvocationRecord's issues.
+func parseIssueSummaries(data []byte) (errs, warnings []Event) {
+	root := gjson.ParseBytes(data)
+
+	root.Get("issues.errorSummaries._values").ForEach(func(_, v gjson.Result) bool {
+		errs = append(errs, issueEvent(EventError, v))
+		return true
+	})
+	root.Get("issues.warningSummaries._values").ForEach(func(_, v gjson.Result) bool {
+		warnings = append(warnings, issueEvent(EventWarning, v))
+		return true
+	})
+
+	return errs, warnings
+}
+
+func issueEvent(t EventType, v gjson.Result) Event {
+	ev := Event{
+		Type:     t,
+		Message:  v.Get("message._value").String(),
+		Category: v.Get("issueType._value").String(),
+	}
+	ev.File, ev.Line, ev.Column = parseLocationURL(v.Get("documentLocationInCreatingWorkspace.url._value").String())
+	return ev
+}
+
+// parseLocationURL decodes a DocumentLocation URL such as
+// file:///a/b.swift#StartingColumnNumber=4&StartingLineNumber=9. The
+// numbers are zero-based in the bundle and returned one-based.
+func parseLocationURL(raw string) (file string, line, column int) {
+	if raw == "" {
+		return "", 0, 0
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw, 0, 0
+	}
+
+	file = u.Path
+	params, _ := url.ParseQuery(u.Fragment)
+	if n, err := strconv.Atoi(params.Get("StartingLineNumber")); err == nil {
+		line = n + 1
+	}
+	if n, err := strconv.Atoi(params.Get("StartingColumnNumber")); err == nil {
+		column = n + 1
+	}
+	return file, line, column
+}
+
+// parseLogNotes walks an ActivityLogSection tree and returns the annotations
+// of each message, keyed by message title.
+func parseLogNotes(data []byte) map[string][]Note {
+	notes := make(map[string][]Note)
+
+	var walk func(section gjson.Result)
+	walk = func(section gjson.Result) {
+		section.Get("messages._values").ForEach(func(_, msg gjson.Result) bool {
+			title := msg.Get("title._value").String()
+			msg.Get("annotations._values").ForEach(func(_, ann gjson.Result) bool {
+				n := Note{Message: ann.Get("title._value").String()}
+				n.File, n.Line, n.Column = parseLocationURL(ann.Get("location.url._value").String())
+				notes[title] = append(notes[title], n)
+				return true
+			})
+			return true
+		})
+		section.Get("subsections._values").ForEach(func(_, sub gjson.Result) bool {
+			walk(sub)
+			return true
+		})
+	}
+	walk(gjson.ParseBytes(data))
+
+	return notes
+}
+
+func attachNotes(events []Event, notes map[string][]Note) {
+	for i := range events {
+		events[i].Notes = notes[events[i].Message]
+	}
+}
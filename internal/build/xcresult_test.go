@@ -0,0 +1,144 @@
+package build
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/project"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseLocationURL(t *testing.T) {
+	tests := []struct {
+		raw          string
+		file         string
+		line, column int
+	}{
+		{"", "", 0, 0},
+		{"file:///a/b.swift#StartingColumnNumber=4&StartingLineNumber=9", "/a/b.swift", 10, 5},
+		{"file:///a/My%20App/b.swift#StartingLineNumber=0", "/a/My App/b.swift", 1, 0},
+		{"file:///a/b.swift", "/a/b.swift", 0, 0},
+		{"file:///a/b.swift#StartingLineNumber=x", "/a/b.swift", 0, 0},
+	}
+	for _, tt := range tests {
+		file, line, column := parseLocationURL(tt.raw)
+		if file != tt.file || line != tt.line || column != tt.column {
+			t.Errorf("parseLocationURL(%q) = %q, %d, %d; want %q, %d, %d", tt.raw, file, line, column, tt.file, tt.line, tt.column)
+		}
+	}
+}
+
+func TestParseIssueSummaries(t *testing.T) {
+	data := []byte(readFixture(t, "xcresult_build.json"))
+
+	errs, warnings := parseIssueSummaries(data)
+	wantErrs := []Event{
+		{
+			Type:     EventError,
+			Message:  "cannot find 'fetchItems' in scope",
+			File:     "/Users/dev/MyApp/Sources/Content View.swift",
+			Line:     42,
+			Column:   18,
+			Category: "Swift Compiler Error",
+		},
+		{
+			Type:     EventError,
+			Message:  "Undefined symbol: _OBJC_CLASS_$_Analytics",
+			Category: "Link Error",
+		},
+	}
+	wantWarnings := []Event{
+		{
+			Type:     EventWarning,
+			Message:  "initialization of immutable value 'count' was never used",
+			File:     "/Users/dev/MyApp/Sources/Model.swift",
+			Line:     12,
+			Column:   9,
+			Category: "Swift Compiler Warning",
+		},
+	}
+	if !reflect.DeepEqual(errs, wantErrs) {
+		t.Errorf("errors =\n%+v\nwant\n%+v", errs, wantErrs)
+	}
+	if !reflect.DeepEqual(warnings, wantWarnings) {
+		t.Errorf("warnings =\n%+v\nwant\n%+v", warnings, wantWarnings)
+	}
+	if got := parseBuildStatus(data); got != "failed" {
+		t.Errorf("status = %q, want failed", got)
+	}
+}
+
+func TestApplyResultBundle(t *testing.T) {
+	const get = "xcrun xcresulttool get --format json --path /tmp/Build.xcresult"
+	streamed := func() *Result {
+		return &Result{
+			Success: true,
+			Errors:  []Event{{Type: EventError, Message: "error: from a script phase"}},
+		}
+	}
+
+	t.Run("bundle is authoritative", func(t *testing.T) {
+		fake := process.NewFakeExecutor().
+			On(get, process.FakeResponse{Stdout: readFixture(t, "xcresult_build.json")}).
+			On(get+" --id 0~bDq3yjZxk2aWGPsXtfm9n3Z8fHGrZzW4L3Hc1lEiH9UT", process.FakeResponse{Stdout: `{
+				"messages": {"_values": [{
+					"title": {"_value": "Undefined symbol: _OBJC_CLASS_$_Analytics"},
+					"annotations": {"_values": [{"title": {"_value": "referenced from Tracker.o"}}]}
+				}]}
+			}`})
+		b := NewBuilder(&project.ProjectInfo{}, process.WithExecutor(fake))
+
+		result := streamed()
+		if !b.applyResultBundle(context.Background(), "/tmp/Build.xcresult", result) {
+			t.Fatal("bundle not read")
+		}
+		if result.Success {
+			t.Error("success despite a failed build status")
+		}
+		if len(result.Errors) != 2 || len(result.Warnings) != 1 {
+			t.Fatalf("got %d errors and %d warnings, want 2 and 1", len(result.Errors), len(result.Warnings))
+		}
+		if want := []Note{{Message: "referenced from Tracker.o"}}; !reflect.DeepEqual(result.Errors[1].Notes, want) {
+			t.Errorf("notes = %+v, want %+v", result.Errors[1].Notes, want)
+		}
+	})
+
+	t.Run("clean bundle clears streamed errors", func(t *testing.T) {
+		fake := process.NewFakeExecutor().On(get, process.FakeResponse{Stdout: readFixture(t, "xcresult_clean.json")})
+		b := NewBuilder(&project.ProjectInfo{}, process.WithExecutor(fake))
+
+		result := streamed()
+		result.Success = false
+		if !b.applyResultBundle(context.Background(), "/tmp/Build.xcresult", result) {
+			t.Fatal("bundle not read")
+		}
+		if !result.Success || len(result.Errors) != 0 || len(result.Warnings) != 0 {
+			t.Errorf("result = %+v, want success without diagnostics", result)
+		}
+	})
+
+	t.Run("unreadable bundle keeps the stream", func(t *testing.T) {
+		fake := process.NewFakeExecutor().On(get+" *", process.FakeResponse{Stderr: "no such bundle", ExitCode: 1}).
+			On(get, process.FakeResponse{Stderr: "no such bundle", ExitCode: 1})
+		b := NewBuilder(&project.ProjectInfo{}, process.WithExecutor(fake))
+
+		result := streamed()
+		if b.applyResultBundle(context.Background(), "/tmp/Build.xcresult", result) {
+			t.Fatal("unreadable bundle reported as read")
+		}
+		if !reflect.DeepEqual(result, streamed()) {
+			t.Errorf("result changed: %+v", result)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+// Package cache manages DerivedData folders: swiftctl's per-project ones
+// and, for cleanup, Xcode's shared ones.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/plist"
+	"github.com/arnavsurve/swiftctl/internal/project"
+)
+
+// Derived data modes accepted by --derived-data.
+const (
+	ModeGlobal = "global" // Xcode's shared ~/Library/Developer/Xcode/DerivedData
+	ModeLocal  = "local"  // .swiftctl/DerivedData in the project root
+	ModeCache  = "cache"  // a per-checkout folder under the user cache dir
+)
+
+// Dir returns swiftctl's cache directory.
+func Dir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "swiftctl"), nil
+}
+
+// DerivedDataRoot is the folder holding per-project DerivedData in cache mode.
+func DerivedDataRoot() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "DerivedData"), nil
+}
+
+// XcodeDerivedData returns Xcode's shared DerivedData folder.
+func XcodeDerivedData() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "Developer", "Xcode", "DerivedData"), nil
+}
+
+// LocalDerivedData is the DerivedData folder used in local mode.
+func LocalDerivedData(proj *project.ProjectInfo) string {
+	return filepath.Join(proj.Root(), ".swiftctl", "DerivedData")
+}
+
+// DerivedDataPath resolves a --derived-data value for proj. It returns ""
+// for the global mode, leaving the location to xcodebuild. Values that
+// aren't a mode are taken as a path.
+func DerivedDataPath(mode string, proj *project.ProjectInfo) (string, error) {
+	switch mode {
+	case "", ModeGlobal:
+		return "", nil
+
+	case ModeLocal:
+		return LocalDerivedData(proj), nil
+
+	case ModeCache:
+		root, err := DerivedDataRoot()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(root, cacheName(proj)), nil
+
+	default:
+		return filepath.Abs(mode)
+	}
+}
+
+// cacheName is Name-<hash of the project path>, so two checkouts of the same
+// project get separate folders.
+func cacheName(proj *project.ProjectInfo) string {
+	path, err := filepath.Abs(proj.Path)
+	if err != nil {
+		path = proj.Path
+	}
+	sum := sha256.Sum256([]byte(path))
+	return proj.Name + "-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// Entry is one DerivedData folder.
+type Entry struct {
+	Path      string    `json:"path"`
+	Location  string    `json:"location"`            // "cache", "local" or "xcode"
+	Workspace string    `json:"workspace,omitempty"` // project it belongs to, when known
+	Size      int64     `json:"size"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// Stale reports whether the entry hasn't been touched for longer than age.
+func (e Entry) Stale(age time.Duration, now time.Time) bool {
+	return now.Sub(e.LastUsed) > age
+}
+
+// List returns the DerivedData folders in swiftctl's cache, the local folder
+// of proj (when non-nil and present) and, if includeXcode is set, Xcode's
+// shared DerivedData. Entries are sorted by last use, oldest first.
+func List(proj *project.ProjectInfo, includeXcode bool) ([]Entry, error) {
+	var entries []Entry
+
+	root, err := DerivedDataRoot()
+	if err != nil {
+		return nil, err
+	}
+	found, err := listRoot(root, ModeCache)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, found...)
+
+	if proj != nil {
+		local := LocalDerivedData(proj)
+		if _, err := os.Stat(local); err == nil {
+			e := inspect(local, ModeLocal)
+			e.Workspace = proj.Path
+			entries = append(entries, e)
+		}
+	}
+
+	if includeXcode {
+		xcode, err := XcodeDerivedData()
+		if err != nil {
+			return nil, err
+		}
+		found, err := listRoot(xcode, "xcode")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, found...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	return entries, nil
+}
+
+// ForProject returns the entries that belong to proj: its local and cache
+// folders, and Xcode DerivedData folders whose info.plist points at it.
+func ForProject(proj *project.ProjectInfo) ([]Entry, error) {
+	entries, err := List(proj, true)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(proj.Path)
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := DerivedDataPath(ModeCache, proj)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Entry
+	for _, e := range entries {
+		switch {
+		case e.Location == ModeLocal, e.Path == cacheDir, e.Workspace == path:
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func listRoot(root, location string) ([]Entry, error) {
+	dirents, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, d := range dirents {
+		// Xcode keeps ModuleCache.noindex and friends next to project folders.
+		if !d.IsDir() || strings.HasSuffix(d.Name(), ".noindex") {
+			continue
+		}
+		entries = append(entries, inspect(filepath.Join(root, d.Name()), location))
+	}
+	return entries, nil
+}
+
+// inspect sizes a folder and finds when it was last written to.
+func inspect(path, location string) Entry {
+	e := Entry{Path: path, Location: location}
+
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			e.Size += info.Size()
+		}
+		if info.ModTime().After(e.LastUsed) {
+			e.LastUsed = info.ModTime()
+		}
+		return nil
+	})
+
+	// Xcode records the workspace each DerivedData folder belongs to.
+	if dict, err := plist.ReadDict(filepath.Join(path, "info.plist")); err == nil {
+		e.Workspace, _ = dict["WorkspacePath"].(string)
+	}
+
+	return e
+}
+
+// Remove deletes a DerivedData folder.
+func Remove(e Entry) error {
+	if err := os.RemoveAll(e.Path); err != nil {
+		return fmt.Errorf("remove %s: %w", e.Path, err)
+	}
+	return nil
+}
+
+// FormatSize renders a byte count for humans, e.g. "1.2 GB".
+func FormatSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -3,14 +3,19 @@ package cli
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/cache"
 	"github.com/arnavsurve/swiftctl/internal/device"
 	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/report"
 	"github.com/arnavsurve/swiftctl/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+const derivedDataUsage = "DerivedData location: global (Xcode's shared folder, default), local (.swiftctl/DerivedData), cache, or a path"
+
 func buildCmd() *cobra.Command {
 	var (
 		scheme      string
@@ -18,6 +23,9 @@ func buildCmd() *cobra.Command {
 		platform    string
 		destination string
 		clean       bool
+		reports     []string
+		sarifPath   string
+		derivedData string
 	)
 
 	cmd := &cobra.Command{
@@ -28,33 +36,50 @@ func buildCmd() *cobra.Command {
   swiftctl build -s MyScheme
   swiftctl build -c release
   swiftctl build --platform ios
-  swiftctl build --clean`,
+  swiftctl build --clean
+  swiftctl build --derived-data local
+  swiftctl build --report junit=build/junit.xml --report json=build/result.json
+  swiftctl build --sarif build/swiftctl.sarif`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			renderer := ui.NewRenderer()
 
+			specs, err := parseReportSpecs(reports)
+			if err != nil {
+				return err
+			}
+			if sarifPath != "" {
+				specs = append(specs, report.Spec{Format: "sarif", Path: sarifPath})
+			}
+
 			detector := project.NewDetector()
 			proj, err := detector.Detect(".")
 			if err != nil {
 				return fmt.Errorf("no project found: %w", err)
 			}
 
-			builder := build.NewBuilder(proj)
+			settings, err := loadConfig(cmd, proj)
+			if err != nil {
+				return err
+			}
 
-			cfg := build.Config{
-				Scheme:      scheme,
-				Destination: destination,
+			derivedDataPath, err := cache.DerivedDataPath(settings.DerivedData, proj)
+			if err != nil {
+				return err
 			}
 
-			switch config {
-			case "release", "Release":
-				cfg.Configuration = build.ConfigRelease
-			default:
-				cfg.Configuration = build.ConfigDebug
+			builder := build.NewBuilder(proj)
+
+			cfg := build.Config{
+				Scheme:        settings.Scheme,
+				Configuration: buildConfiguration(settings.Configuration),
+				Destination:   destination,
+				DerivedData:   derivedDataPath,
+				ExtraArgs:     settings.XcodebuildArgs,
 			}
 
-			if platform != "" {
-				cfg.Platform = device.Platform(platform)
+			if settings.Platform != "" {
+				cfg.Platform = device.Platform(settings.Platform)
 			} else if len(proj.Platforms) > 0 {
 				cfg.Platform = proj.Platforms[0]
 			}
@@ -69,7 +94,7 @@ func buildCmd() *cobra.Command {
 				}
 			}
 
-			schemeName := scheme
+			schemeName := cfg.Scheme
 			if schemeName == "" && len(proj.Schemes) > 0 {
 				schemeName = proj.Schemes[0]
 			}
@@ -77,13 +102,13 @@ func buildCmd() *cobra.Command {
 				schemeName = proj.Name
 			}
 
+			renderer.StartGroup("Build %s", schemeName)
 			renderer.StartSpinner("Building %s...", schemeName)
 
 			events := make(chan build.Event, 100)
 			done := make(chan struct{})
 
 			var lastFile string
-			var warningCount, errorCount int
 
 			go func() {
 				for ev := range events {
@@ -93,11 +118,11 @@ func buildCmd() *cobra.Command {
 						renderer.StopSpinner(true)
 						renderer.StartSpinner("Compiling %s...", lastFile)
 
-					case build.EventWarning:
-						warningCount++
-
 					case build.EventError:
-						errorCount++
+						if renderer.CI() {
+							// Annotated from the final result instead.
+							continue
+						}
 						renderer.StopSpinner(false)
 						renderer.Error("%s:%d: %s", filepath.Base(ev.File), ev.Line, ev.Message)
 						renderer.StartSpinner("Building...")
@@ -119,6 +144,16 @@ func buildCmd() *cobra.Command {
 			<-done
 
 			renderer.StopSpinner(result != nil && result.Success)
+			renderer.EndGroup()
+
+			// A dry run built nothing, so there is nothing to summarize.
+			if result != nil && plan == nil {
+				annotateDiagnostics(renderer, result)
+				if err := renderer.WriteSummary(buildSummary(schemeName, result)); err != nil {
+					renderer.Warning("Job summary: %v", err)
+				}
+				writeReports(renderer, specs, report.Report{Build: result, Root: proj.Root()})
+			}
 
 			if err != nil {
 				return fmt.Errorf("build failed: %w", err)
@@ -126,17 +161,23 @@ func buildCmd() *cobra.Command {
 
 			if result.Success {
 				renderer.Success("Build succeeded in %.1fs", result.Duration.Seconds())
-				if warningCount > 0 {
-					renderer.Warning("%d warning(s)", warningCount)
+				if result.ProductPath != "" {
+					renderer.Dim("%s", result.ProductPath)
+				}
+				if len(result.Warnings) > 0 {
+					renderer.Warning("%d warning(s)", len(result.Warnings))
 				}
 			} else {
-				renderer.Error("Build failed with %d error(s)", errorCount)
+				renderer.Error("Build failed with %d error(s)", len(result.Errors))
 				for i, e := range result.Errors {
 					if i >= 5 {
 						renderer.Info("... and %d more errors", len(result.Errors)-5)
 						break
 					}
 					renderer.Info("  %s:%d: %s", filepath.Base(e.File), e.Line, e.Message)
+					for _, n := range e.Notes {
+						renderer.Dim("    note: %s", n.Message)
+					}
 				}
 				return fmt.Errorf("build failed")
 			}
@@ -150,6 +191,69 @@ func buildCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&platform, "platform", "p", "", "Target platform (ios, macos, etc.)")
 	cmd.Flags().StringVar(&destination, "destination", "", "Build destination (xcodebuild format)")
 	cmd.Flags().BoolVar(&clean, "clean", false, "Clean before building")
+	cmd.Flags().StringVar(&derivedData, "derived-data", "", derivedDataUsage)
+	cmd.Flags().StringArrayVar(&reports, "report", nil, "Write a report as format=path (junit, json, sarif); repeatable")
+	cmd.Flags().StringVar(&sarifPath, "sarif", "", "Write compiler diagnostics as SARIF 2.1.0 (same as --report sarif=path)")
 
 	return cmd
 }
+
+func parseReportSpecs(values []string) ([]report.Spec, error) {
+	specs := make([]report.Spec, 0, len(values))
+	for _, v := range values {
+		spec, err := report.ParseSpec(v)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// writeReports writes every requested report. Failures are reported but
+// don't change the command's outcome.
+func writeReports(renderer *ui.Renderer, specs []report.Spec, r report.Report) {
+	for _, spec := range specs {
+		if err := report.WriteFile(spec, r); err != nil {
+			renderer.Warning("Report %s: %v", spec.Path, err)
+			continue
+		}
+		renderer.Dim("Wrote %s report to %s", spec.Format, spec.Path)
+	}
+}
+
+// annotateDiagnostics emits CI annotations for a build's final diagnostics.
+func annotateDiagnostics(renderer *ui.Renderer, result *build.Result) {
+	for _, e := range result.Errors {
+		renderer.Annotate("error", e.File, e.Line, e.Column, e.Message)
+	}
+	for _, e := range result.Warnings {
+		renderer.Annotate("warning", e.File, e.Line, e.Column, e.Message)
+	}
+}
+
+// buildSummary renders a markdown job summary for a build.
+func buildSummary(scheme string, result *build.Result) string {
+	var b strings.Builder
+
+	status := "✅ succeeded"
+	if !result.Success {
+		status = "❌ failed"
+	}
+	fmt.Fprintf(&b, "### swiftctl build %s: %s\n\n", scheme, status)
+	b.WriteString("| Duration | Errors | Warnings |\n|---|---|---|\n")
+	fmt.Fprintf(&b, "| %.1fs | %d | %d |\n\n", result.Duration.Seconds(), len(result.Errors), len(result.Warnings))
+
+	for i, e := range result.Errors {
+		if i >= 10 {
+			fmt.Fprintf(&b, "- ... and %d more errors\n", len(result.Errors)-10)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s:%d`: %s\n", filepath.Base(e.File), e.Line, e.Message)
+	}
+	if len(result.Errors) > 0 {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/cache"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func cacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage DerivedData caches",
+		Long: `List and prune DerivedData folders created with --derived-data cache or local.
+
+Pass --xcode to include Xcode's shared DerivedData as well.`,
+	}
+
+	cmd.AddCommand(cacheListCmd())
+	cmd.AddCommand(cachePruneCmd())
+
+	return cmd
+}
+
+// currentProject returns the project in the working directory, or nil.
+func currentProject() *project.ProjectInfo {
+	proj, err := project.NewDetector().Detect(".")
+	if err != nil {
+		return nil
+	}
+	return proj
+}
+
+func cacheListCmd() *cobra.Command {
+	var (
+		xcode   bool
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List DerivedData folders with their size and last use",
+		Example: `  swiftctl cache ls
+  swiftctl cache ls --xcode
+  swiftctl cache ls --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := cache.List(currentProject(), xcode)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(append([]cache.Entry{}, entries...))
+			}
+
+			renderer := ui.NewRenderer()
+			if len(entries) == 0 {
+				renderer.Info("No DerivedData folders found")
+				return nil
+			}
+
+			var total int64
+			now := time.Now()
+			for _, e := range entries {
+				total += e.Size
+				renderer.Info("%9s  %-6s  %-8s  %s", cache.FormatSize(e.Size), e.Location, age(now.Sub(e.LastUsed)), e.Path)
+				if e.Workspace != "" {
+					renderer.Dim("%31s%s", "", e.Workspace)
+				}
+			}
+			renderer.Info("")
+			renderer.Info("%d folder(s), %s total", len(entries), cache.FormatSize(total))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&xcode, "xcode", false, "Include Xcode's shared DerivedData")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func cachePruneCmd() *cobra.Command {
+	var (
+		olderThan int
+		xcode     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove DerivedData folders not used for a while",
+		Example: `  swiftctl cache prune
+  swiftctl cache prune --older-than 7
+  swiftctl cache prune --xcode`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if olderThan < 0 {
+				return fmt.Errorf("--older-than must not be negative")
+			}
+
+			entries, err := cache.List(currentProject(), xcode)
+			if err != nil {
+				return err
+			}
+
+			maxAge := time.Duration(olderThan) * 24 * time.Hour
+			now := time.Now()
+
+			var stale []cache.Entry
+			for _, e := range entries {
+				if e.Stale(maxAge, now) {
+					stale = append(stale, e)
+				}
+			}
+
+			renderer := ui.NewRenderer()
+			if len(stale) == 0 {
+				renderer.Info("Nothing unused for more than %d day(s)", olderThan)
+				return nil
+			}
+			return removeEntries(renderer, stale)
+		},
+	}
+
+	cmd.Flags().IntVar(&olderThan, "older-than", 30, "Remove folders unused for more than this many days")
+	cmd.Flags().BoolVar(&xcode, "xcode", false, "Include Xcode's shared DerivedData")
+
+	return cmd
+}
+
+// age renders how long ago something happened, e.g. "3d" or "5h".
+func age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
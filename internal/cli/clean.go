@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/cache"
+	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func cleanCmd() *cobra.Command {
+	var (
+		scheme      string
+		config      string
+		derivedData bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Clean build products",
+		Long: `Run xcodebuild clean (or swift package clean) for the project.
+
+With --derived-data, remove every DerivedData folder that belongs to this
+checkout instead: the local and cache folders used by --derived-data, and
+folders in Xcode's shared DerivedData whose workspace is this project.`,
+		Example: `  swiftctl clean
+  swiftctl clean -s MyScheme
+  swiftctl clean --derived-data`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			renderer := ui.NewRenderer()
+
+			detector := project.NewDetector()
+			proj, err := detector.Detect(".")
+			if err != nil {
+				return fmt.Errorf("no project found: %w", err)
+			}
+
+			if derivedData {
+				entries, err := cache.ForProject(proj)
+				if err != nil {
+					return err
+				}
+				if len(entries) == 0 {
+					renderer.Info("No DerivedData found for %s", proj.Name)
+					return nil
+				}
+				return removeEntries(renderer, entries)
+			}
+
+			settings, err := loadConfig(cmd, proj)
+			if err != nil {
+				return err
+			}
+
+			derivedDataPath, err := cache.DerivedDataPath(settings.DerivedData, proj)
+			if err != nil {
+				return err
+			}
+
+			cfg := build.Config{
+				Scheme:        settings.Scheme,
+				Configuration: buildConfiguration(settings.Configuration),
+				DerivedData:   derivedDataPath,
+				ExtraArgs:     settings.XcodebuildArgs,
+			}
+
+			renderer.StartSpinner("Cleaning %s...", proj.Name)
+			if err := build.NewBuilder(proj).Clean(ctx, cfg); err != nil {
+				renderer.StopSpinner(false)
+				return fmt.Errorf("clean failed: %w", err)
+			}
+			renderer.StopSpinner(true)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&scheme, "scheme", "s", "", "Scheme to clean")
+	cmd.Flags().StringVarP(&config, "configuration", "c", "debug", "Build configuration (debug/release)")
+	cmd.Flags().BoolVar(&derivedData, "derived-data", false, "Remove this project's DerivedData folders")
+
+	return cmd
+}
+
+// removeEntries deletes DerivedData folders, or adds them to the plan
+// during a dry run.
+func removeEntries(renderer *ui.Renderer, entries []cache.Entry) error {
+	var freed int64
+	for _, e := range entries {
+		if plan != nil {
+			plan.Add(process.Command{Name: "rm", Args: []string{"-rf", e.Path}})
+			continue
+		}
+		if err := cache.Remove(e); err != nil {
+			return err
+		}
+		freed += e.Size
+		renderer.Success("Removed %s (%s)", e.Path, cache.FormatSize(e.Size))
+	}
+
+	if plan == nil {
+		renderer.Info("Freed %s", cache.FormatSize(freed))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/config"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// configFlags maps config keys to the command flags that override them.
+var configFlags = map[string]string{
+	"scheme":        "scheme",
+	"configuration": "configuration",
+	"platform":      "platform",
+	"device":        "device",
+	"devices":       "devices",
+	"args":          "args",
+	"derived_data":  "derived-data",
+
+	"env":               "env",
+	"wait_for_debugger": "wait-for-debugger",
+	"stdout":            "stdout",
+	"stderr":            "stderr",
+	"console_pty":       "console-pty",
+}
+
+// loadConfig resolves .swiftctl.yaml and the --profile for proj, then
+// applies any flags the user set on cmd.
+func loadConfig(cmd *cobra.Command, proj *project.ProjectInfo) (*config.Resolved, error) {
+	file, path, err := config.Load(proj.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := file.Resolve(path, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range config.Keys {
+		name, ok := configFlags[key]
+		if !ok {
+			continue
+		}
+		f := cmd.Flags().Lookup(name)
+		if f == nil || !f.Changed {
+			continue
+		}
+
+		var value any = f.Value.String()
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			value = sv.GetSlice()
+		}
+		if err := res.Override(key, value, "flag --"+name); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// buildConfiguration maps a configuration name to build.Configuration.
+func buildConfiguration(name string) build.Configuration {
+	switch strings.ToLower(name) {
+	case "release":
+		return build.ConfigRelease
+	default:
+		return build.ConfigDebug
+	}
+}
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect project configuration",
+		Long: `Inspect the project configuration in ` + config.FileName + `.
+
+The file sits next to the project and sets defaults for scheme, configuration,
+platform, device(s), launch options (args, env, debugger, output redirection),
+extra xcodebuild args, watch patterns, DerivedData location and the source
+map used by 'swiftctl debug'. Named profiles under "profiles:" are selected
+with --profile; command-line flags override both.`,
+	}
+
+	cmd.AddCommand(configShowCmd())
+
+	return cmd
+}
+
+func configShowCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the effective configuration and where each value comes from",
+		Example: `  swiftctl config show
+  swiftctl config show --profile qa
+  swiftctl config show --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			detector := project.NewDetector()
+			proj, err := detector.Detect(".")
+			if err != nil {
+				return fmt.Errorf("no project found: %w", err)
+			}
+
+			res, err := loadConfig(cmd, proj)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(struct {
+					Profile  string            `json:"profile,omitempty"`
+					Settings config.Settings   `json:"settings"`
+					Sources  map[string]string `json:"sources"`
+				}{res.Profile, res.Settings, res.Sources})
+			}
+
+			renderer := ui.NewRenderer()
+			if res.Profile != "" {
+				renderer.Info("Profile: %s", res.Profile)
+			}
+			for _, key := range config.Keys {
+				value := settingString(res, key)
+				source, ok := res.Sources[key]
+				if !ok {
+					renderer.Dim("%-16s (unset)", key)
+					continue
+				}
+				renderer.Info("%-16s %s  (%s)", key, value, source)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func settingString(res *config.Resolved, key string) string {
+	switch key {
+	case "scheme":
+		return res.Scheme
+	case "configuration":
+		return res.Configuration
+	case "platform":
+		return res.Platform
+	case "device":
+		return res.Device
+	case "devices":
+		return strings.Join(res.Devices, ", ")
+	case "args":
+		return strings.Join(res.Args, " ")
+	case "env":
+		return strings.Join(res.EnvList(), " ")
+	case "xcodebuild_args":
+		return strings.Join(res.XcodebuildArgs, " ")
+	case "watch":
+		return strings.Join(res.Watch, " ")
+	case "derived_data":
+		return res.DerivedData
+	case "wait_for_debugger":
+		return strconv.FormatBool(res.WaitForDebugger != nil && *res.WaitForDebugger)
+	case "stdout":
+		return res.Stdout
+	case "stderr":
+		return res.Stderr
+	case "console_pty":
+		return strconv.FormatBool(res.ConsolePTY != nil && *res.ConsolePTY)
+	case "source_map":
+		pairs := make([]string, 0, len(res.SourceMap))
+		for from, to := range res.SourceMap {
+			pairs = append(pairs, from+" -> "+to)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ", ")
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/arnavsurve/swiftctl/internal/cache"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/run"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func debugCmd() *cobra.Command {
+	var (
+		scheme        string
+		configuration string
+		deviceName    string
+		watch         bool
+		launchArgs    []string
+		env           []string
+		derivedData   string
+		attach        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "debug [platform]",
+		Short: "Build, launch and debug the app with lldb",
+		Long: `Build and install the app like 'swiftctl run', launch it suspended waiting
+for a debugger, then start lldb attached to it in the foreground. Set
+breakpoints and 'continue' to run the app; 'quit' ends the session.
+
+Simulators are attached to directly; on physical devices lldb goes through
+CoreDevice, which starts debugserver on the device.
+
+With -w, quitting lldb goes back to watching for changes; the next change
+rebuilds and starts a new debug session.
+
+Use --attach to debug an app that is already running instead, without
+building or relaunching it.
+
+Paths recorded at build time that differ from this checkout (CI or cached
+builds) can be mapped with source_map in .swiftctl.yaml.`,
+		Example: `  swiftctl debug ios
+  swiftctl debug ios -d "iPhone 15 Pro" -w
+  swiftctl debug ios -e API_URL=http://localhost:8080
+  swiftctl debug ios --attach com.example.MyApp
+  swiftctl debug ios -d "My iPhone"`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"ios", "watchos", "tvos", "visionos"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			renderer := ui.NewRenderer()
+
+			detector := project.NewDetector()
+			proj, err := detector.Detect(".")
+			if err != nil {
+				return fmt.Errorf("no project found: %w", err)
+			}
+
+			settings, err := loadConfig(cmd, proj)
+			if err != nil {
+				return err
+			}
+			platform, err := runPlatform(cmd, settings, proj, args)
+			if err != nil {
+				return err
+			}
+
+			if attach != "" && watch {
+				return fmt.Errorf("--attach can't be combined with --watch")
+			}
+
+			renderer.Info("Project: %s (%s)", proj.Name, proj.Type)
+			if settings.Profile != "" {
+				renderer.Info("Profile: %s", settings.Profile)
+			}
+
+			derivedDataPath, err := cache.DerivedDataPath(settings.DerivedData, proj)
+			if err != nil {
+				return err
+			}
+
+			cfg := run.Config{
+				Scheme:        settings.Scheme,
+				Configuration: buildConfiguration(settings.Configuration),
+				Platform:      platform,
+				Devices:       settings.DeviceNames(),
+				Watch:         watch,
+				Launch:        settings.LaunchOptions(),
+				ExtraArgs:     settings.XcodebuildArgs,
+				WatchPatterns: settings.Watch,
+				DerivedData:   derivedDataPath,
+				SourceMap:     settings.SourceMap,
+			}
+
+			runner := run.NewRunner(proj)
+			if attach != "" {
+				return runner.Attach(ctx, cfg, attach)
+			}
+			return runner.Debug(ctx, cfg)
+		},
+	}
+
+	cmd.Flags().StringVarP(&scheme, "scheme", "s", "", "Scheme to build (default: first available)")
+	cmd.Flags().StringVarP(&configuration, "configuration", "c", "debug", "Build configuration (debug/release)")
+	cmd.Flags().StringVarP(&deviceName, "device", "d", "", "Target device name or UDID")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "After lldb exits, rebuild and debug again on file changes")
+	cmd.Flags().StringSliceVar(&launchArgs, "args", nil, "Arguments to pass to the launched app")
+	cmd.Flags().StringArrayVarP(&env, "env", "e", nil, "Environment variable for the app as KEY=VALUE; repeatable")
+	cmd.Flags().StringVar(&derivedData, "derived-data", "", derivedDataUsage)
+	cmd.Flags().StringVar(&attach, "attach", "", "Attach to this already running app (bundle ID) instead of building and launching")
+
+	return cmd
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/arnavsurve/swiftctl/internal/device"
 	"github.com/arnavsurve/swiftctl/internal/ui"
@@ -16,14 +17,20 @@ func devicesCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "devices",
 		Short: "Manage simulators and devices",
-		Long:  `List, boot, shutdown, and create iOS/macOS simulators.`,
+		Long:  `List, boot, shutdown, create, erase, clone, rename and sync iOS/macOS simulators.`,
 	}
 
 	cmd.AddCommand(devicesListCmd())
 	cmd.AddCommand(devicesBootCmd())
 	cmd.AddCommand(devicesShutdownCmd())
+	cmd.AddCommand(devicesWaitCmd())
+	cmd.AddCommand(devicesWatchCmd())
 	cmd.AddCommand(devicesCreateCmd())
 	cmd.AddCommand(devicesDeleteCmd())
+	cmd.AddCommand(devicesEraseCmd())
+	cmd.AddCommand(devicesCloneCmd())
+	cmd.AddCommand(devicesRenameCmd())
+	cmd.AddCommand(devicesSyncCmd())
 	cmd.AddCommand(devicesTypesCmd())
 	cmd.AddCommand(devicesRuntimesCmd())
 
@@ -39,7 +46,7 @@ func devicesListCmd() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List available simulators",
+		Short: "List available simulators and connected devices",
 		Example: `  swiftctl devices list
   swiftctl devices list --platform ios
   swiftctl devices list --booted
@@ -84,12 +91,24 @@ func devicesListCmd() *cobra.Command {
 }
 
 func devicesBootCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		wait     bool
+		headless bool
+		timeout  time.Duration
+	)
+
+	cmd := &cobra.Command{
 		Use:   "boot <device>",
 		Short: "Boot a simulator",
-		Long:  `Boot a simulator by name or UDID.`,
+		Long: `Boot a simulator by name or UDID.
+
+By default this returns as soon as the simulator starts booting. With --wait
+it returns once the simulator is ready to install and launch apps, showing
+each boot stage, and fails after --timeout. --headless skips opening the
+Simulator app, e.g. on CI.`,
 		Example: `  swiftctl devices boot "iPhone 15 Pro"
-  swiftctl devices boot 12345678-1234-1234-1234-123456789ABC`,
+  swiftctl devices boot "iPhone 15 Pro" --wait --headless
+  swiftctl devices boot 12345678-1234-1234-1234-123456789ABC --wait --timeout 5m`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
@@ -103,7 +122,15 @@ func devicesBootCmd() *cobra.Command {
 
 			renderer.StartSpinner("Booting %s...", dev.Name)
 
-			if err := mgr.Boot(ctx, dev); err != nil {
+			opts := device.BootOptions{
+				Wait:     wait,
+				Timeout:  timeout,
+				Headless: headless,
+				Progress: func(stage string) {
+					renderer.UpdateSpinner("Booting %s (waiting on %s)...", dev.Name, stage)
+				},
+			}
+			if err := mgr.Boot(ctx, dev, opts); err != nil {
 				renderer.StopSpinner(false)
 				return fmt.Errorf("failed to boot: %w", err)
 			}
@@ -113,6 +140,12 @@ func devicesBootCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&wait, "wait", false, "Wait until the simulator has finished booting")
+	cmd.Flags().BoolVar(&headless, "headless", false, "Don't open the Simulator app")
+	cmd.Flags().DurationVar(&timeout, "timeout", device.DefaultBootTimeout, "How long --wait waits for the boot to finish")
+
+	return cmd
 }
 
 func devicesShutdownCmd() *cobra.Command {
@@ -157,6 +190,94 @@ func devicesShutdownCmd() *cobra.Command {
 	}
 }
 
+func devicesWaitCmd() *cobra.Command {
+	var (
+		state   string
+		timeout time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "wait <device>",
+		Short: "Wait until a device reaches a state",
+		Long: `Poll a simulator or device until it reaches --state, for shell scripts.
+
+Exits non-zero if the state isn't reached within --timeout. States are
+booted, booting, shutdown and shutting-down for simulators, and connected
+or disconnected for physical devices.`,
+		Example: `  swiftctl devices wait "iPhone 15 Pro" --state booted --timeout 2m
+  swiftctl devices wait "iPhone 15 Pro" --state shutdown
+  swiftctl devices wait "My iPhone" --state connected`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			mgr := device.NewManager()
+			renderer := ui.NewRenderer()
+
+			want, err := device.ParseState(state)
+			if err != nil {
+				return err
+			}
+
+			dev, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("device not found: %w", err)
+			}
+
+			if dev.State != want {
+				renderer.StartSpinner("Waiting for %s to be %s...", dev.Name, want)
+				if err := mgr.WaitForState(ctx, dev, want, timeout); err != nil {
+					renderer.StopSpinner(false)
+					return err
+				}
+				renderer.StopSpinner(true)
+			}
+			renderer.Success("%s is %s", dev.Name, dev.State)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&state, "state", "booted", "State to wait for")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait before failing")
+
+	return cmd
+}
+
+func devicesWatchCmd() *cobra.Command {
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print simulator state changes as JSON lines",
+		Long: `Watch the simulators and print an event as one JSON line on stdout
+whenever one is created or deleted, or changes state (boots, shuts down).
+
+Each event has time, type (created, deleted or state), udid, name, platform,
+os_version, state and, for state changes, previous_state. Runs until
+interrupted.`,
+		Example: `  swiftctl devices watch
+  swiftctl devices watch | jq -r 'select(.state == "Booted") | .name'
+  swiftctl devices watch --interval 5s`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			mgr := device.NewManager()
+
+			events, errs := mgr.Watch(ctx, interval)
+			enc := json.NewEncoder(os.Stdout)
+			for ev := range events {
+				if err := enc.Encode(ev); err != nil {
+					return err
+				}
+			}
+			return <-errs
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often to poll the simulator list")
+
+	return cmd
+}
+
 func devicesCreateCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "create <name> <device-type> <runtime>",
@@ -223,6 +344,392 @@ func devicesDeleteCmd() *cobra.Command {
 	}
 }
 
+func devicesEraseCmd() *cobra.Command {
+	var shutdownFirst bool
+
+	cmd := &cobra.Command{
+		Use:   "erase <device|all>",
+		Short: "Erase a simulator's contents and settings",
+		Long: `Reset a simulator (or all of them) to a clean state, removing installed
+apps, data and settings.
+
+Simulators must be shut down to be erased. Booted ones are refused unless
+--shutdown-first is given, which shuts them down and waits before erasing.`,
+		Example: `  swiftctl devices erase "iPhone 15 Pro"
+  swiftctl devices erase "CI-1" --shutdown-first
+  swiftctl devices erase all --shutdown-first`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			mgr := device.NewManager()
+			renderer := ui.NewRenderer()
+
+			if args[0] == "all" {
+				devices, err := mgr.List(ctx, "", false)
+				if err != nil {
+					return fmt.Errorf("failed to list devices: %w", err)
+				}
+				for _, dev := range devices {
+					if dev.Type != device.DeviceTypeSimulator {
+						continue
+					}
+					if err := ensureShutdown(ctx, mgr, renderer, dev, shutdownFirst); err != nil {
+						return err
+					}
+				}
+
+				renderer.StartSpinner("Erasing all simulators...")
+				if err := mgr.EraseAll(ctx); err != nil {
+					renderer.StopSpinner(false)
+					return fmt.Errorf("failed to erase: %w", err)
+				}
+				renderer.StopSpinner(true)
+				renderer.Success("All simulators erased")
+				return nil
+			}
+
+			dev, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("device not found: %w", err)
+			}
+			if err := ensureShutdown(ctx, mgr, renderer, dev, shutdownFirst); err != nil {
+				return err
+			}
+
+			renderer.StartSpinner("Erasing %s...", dev.Name)
+			if err := mgr.Erase(ctx, dev); err != nil {
+				renderer.StopSpinner(false)
+				return fmt.Errorf("failed to erase: %w", err)
+			}
+			renderer.StopSpinner(true)
+			renderer.Success("Erased %s", dev.Name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&shutdownFirst, "shutdown-first", false, "Shut down booted simulators before erasing them")
+
+	return cmd
+}
+
+func devicesCloneCmd() *cobra.Command {
+	var shutdownFirst bool
+
+	cmd := &cobra.Command{
+		Use:   "clone <device> <new-name>",
+		Short: "Clone a simulator, including its apps and data",
+		Long: `Create a copy of a simulator with everything installed on it.
+
+Set up a "golden" simulator once (apps, accounts, settings), then clone it
+for each test run and delete the clone afterwards. The new simulator's UDID
+is printed on stdout, so scripts can capture it.
+
+The source must be shut down; --shutdown-first shuts it down if needed.`,
+		Example: `  swiftctl devices clone "Golden iPhone" "CI-1"
+  udid=$(swiftctl devices clone "Golden iPhone" "CI-$BUILD_NUMBER" --shutdown-first)`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			mgr := device.NewManager()
+			renderer := ui.NewRenderer()
+
+			dev, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("device not found: %w", err)
+			}
+			name := args[1]
+			if err := checkNameFree(ctx, mgr, name); err != nil {
+				return err
+			}
+			if err := ensureShutdown(ctx, mgr, renderer, dev, shutdownFirst); err != nil {
+				return err
+			}
+
+			renderer.StartSpinner("Cloning %s...", dev.Name)
+			udid, err := mgr.Clone(ctx, dev, name)
+			if err != nil {
+				renderer.StopSpinner(false)
+				return fmt.Errorf("failed to clone: %w", err)
+			}
+			renderer.StopSpinner(true)
+			renderer.Success("Cloned %s as %s (%s)", dev.Name, name, udid)
+			fmt.Println(udid)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&shutdownFirst, "shutdown-first", false, "Shut down the source simulator if it is booted")
+
+	return cmd
+}
+
+func devicesRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rename <device> <new-name>",
+		Short:   "Rename a simulator",
+		Example: `  swiftctl devices rename "iPhone 15 Pro" "Golden iPhone"`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			mgr := device.NewManager()
+			renderer := ui.NewRenderer()
+
+			dev, err := mgr.Get(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("device not found: %w", err)
+			}
+			name := args[1]
+			if err := checkNameFree(ctx, mgr, name); err != nil {
+				return err
+			}
+
+			old := dev.Name
+			if err := mgr.Rename(ctx, dev, name); err != nil {
+				return fmt.Errorf("failed to rename: %w", err)
+			}
+			renderer.Success("Renamed %s to %s", old, name)
+			return nil
+		},
+	}
+}
+
+func devicesSyncCmd() *cobra.Command {
+	var (
+		file     string
+		planOnly bool
+		prune    bool
+		yes      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Create and delete simulators to match a declarative list",
+		Long: `Make the simulators match a YAML file listing name, device type and runtime:
+
+  simulators:
+    - name: CI iPhone
+      device_type: iPhone 15 Pro
+      runtime: iOS 17.5
+
+Simulators are matched by name. Missing ones are created, ones with another
+device type or runtime, or whose runtime is no longer installed, are deleted
+and recreated, and extra simulators with a declared name are deleted.
+Simulators that aren't in the file are left alone unless --prune is given;
+deleting them also needs --yes.
+
+The plan is printed before anything changes; --plan stops there.`,
+		Example: `  swiftctl devices sync -f simulators.yaml --plan
+  swiftctl devices sync -f simulators.yaml
+  swiftctl devices sync -f simulators.yaml --prune --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			mgr := device.NewManager()
+			renderer := ui.NewRenderer()
+
+			fleet, err := device.LoadFleet(file)
+			if err != nil {
+				return err
+			}
+
+			// Listed once for the whole file rather than per simulator.
+			types, err := mgr.ListDeviceTypes(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list device types: %w", err)
+			}
+			runtimes, err := mgr.ListRuntimes(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list runtimes: %w", err)
+			}
+
+			for i := range fleet.Simulators {
+				spec := &fleet.Simulators[i]
+				if spec.DeviceTypeID, err = findDeviceType(types, spec.DeviceType); err != nil {
+					return fmt.Errorf("%s: %w", spec.Name, err)
+				}
+				if spec.RuntimeID, err = findRuntime(runtimes, spec.Runtime); err != nil {
+					return fmt.Errorf("%s: %w", spec.Name, err)
+				}
+			}
+
+			existing, err := mgr.Simulators(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to list simulators: %w", err)
+			}
+
+			changes := device.PlanSync(fleet.Simulators, existing, prune)
+			pending := printSyncPlan(renderer, changes)
+			unmanaged := unmanagedSimulators(fleet, existing)
+			if !prune && unmanaged > 0 {
+				renderer.Dim("%d simulator(s) not in %s left alone (--prune deletes them)", unmanaged, file)
+			}
+			if pending == 0 {
+				renderer.Success("Simulators are in sync with %s", file)
+				return nil
+			}
+			if planOnly {
+				return nil
+			}
+			if prune && unmanaged > 0 && !yes {
+				return fmt.Errorf("--prune would delete %d simulator(s) not in %s; review the plan and rerun with --yes", unmanaged, file)
+			}
+
+			failed := 0
+			for _, c := range changes {
+				if c.Action == device.ActionKeep {
+					continue
+				}
+				if err := applySyncChange(ctx, mgr, renderer, c); err != nil {
+					renderer.Error("%s %s: %v", c.Action, c.Name, err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("sync failed for %d of %d changes", failed, pending)
+			}
+			renderer.Success("Applied %d change(s)", pending)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "simulators.yaml", "File listing the simulators")
+	cmd.Flags().BoolVar(&planOnly, "plan", false, "Only print the changes that would be made")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Also delete simulators that aren't in the file")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply --prune deletions without stopping at the plan")
+
+	return cmd
+}
+
+// printSyncPlan prints changes as a table and returns how many aren't
+// keeps.
+func printSyncPlan(renderer *ui.Renderer, changes []device.Change) int {
+	// Deletions have no spec, so they show what the simulator is now.
+	describe := func(c device.Change) (deviceType, runtime string) {
+		if c.Spec != nil {
+			return c.Spec.DeviceType, c.Spec.Runtime
+		}
+		return strings.TrimPrefix(c.Device.DeviceTypeID, "com.apple.CoreSimulator.SimDeviceType."),
+			fmt.Sprintf("%s %s", c.Device.Platform, c.Device.OSVersion)
+	}
+
+	nameWidth, typeWidth := len("NAME"), len("DEVICE TYPE")
+	for _, c := range changes {
+		deviceType, _ := describe(c)
+		nameWidth = max(nameWidth, len(c.Name))
+		typeWidth = max(typeWidth, len(deviceType))
+	}
+
+	renderer.Info("%-8s  %-*s  %-*s  %s", "ACTION", nameWidth, "NAME", typeWidth, "DEVICE TYPE", "RUNTIME")
+
+	counts := make(map[string]int)
+	for _, c := range changes {
+		counts[c.Action]++
+
+		deviceType, runtime := describe(c)
+		line := fmt.Sprintf("%-8s  %-*s  %-*s  %s", c.Action, nameWidth, c.Name, typeWidth, deviceType, runtime)
+		if c.Reason != "" {
+			line += "  (" + c.Reason + ")"
+		}
+
+		if c.Action == device.ActionKeep {
+			renderer.Dim("%s", line)
+		} else {
+			renderer.Info("%s", line)
+		}
+	}
+
+	renderer.Info("")
+	renderer.Info("%d to create, %d to recreate, %d to delete, %d unchanged",
+		counts[device.ActionCreate], counts[device.ActionRecreate], counts[device.ActionDelete], counts[device.ActionKeep])
+	return len(changes) - counts[device.ActionKeep]
+}
+
+// unmanagedSimulators counts the simulators whose name fleet doesn't declare.
+func unmanagedSimulators(fleet *device.Fleet, existing []*device.Device) int {
+	declared := make(map[string]bool, len(fleet.Simulators))
+	for _, spec := range fleet.Simulators {
+		declared[spec.Name] = true
+	}
+	n := 0
+	for _, d := range existing {
+		if d.Type == device.DeviceTypeSimulator && !declared[d.Name] {
+			n++
+		}
+	}
+	return n
+}
+
+// applySyncChange carries out one non-keep change, shutting down simulators
+// before deleting them.
+func applySyncChange(ctx context.Context, mgr *device.Manager, renderer *ui.Renderer, c device.Change) error {
+	if c.Action == device.ActionDelete || c.Action == device.ActionRecreate {
+		if err := ensureShutdown(ctx, mgr, renderer, c.Device, true); err != nil {
+			return err
+		}
+		renderer.StartSpinner("Deleting %s...", c.Name)
+		if err := mgr.Delete(ctx, c.Device); err != nil {
+			renderer.StopSpinner(false)
+			return err
+		}
+		renderer.StopSpinner(true)
+		if c.Action == device.ActionDelete {
+			renderer.Success("Deleted %s (%s)", c.Name, c.Device.UDID)
+			return nil
+		}
+	}
+
+	renderer.StartSpinner("Creating %s...", c.Name)
+	udid, err := mgr.Create(ctx, c.Name, c.Spec.DeviceTypeID, c.Spec.RuntimeID)
+	if err != nil {
+		renderer.StopSpinner(false)
+		return err
+	}
+	renderer.StopSpinner(true)
+	renderer.Success("Created %s (%s)", c.Name, udid)
+	return nil
+}
+
+// ensureShutdown makes sure a simulator is shut down before an operation
+// that requires it, shutting it down and waiting when allowed.
+func ensureShutdown(ctx context.Context, mgr *device.Manager, renderer *ui.Renderer, dev *device.Device, shutdownFirst bool) error {
+	if dev.Type != device.DeviceTypeSimulator || dev.State == device.StateShutdown {
+		return nil
+	}
+	if !shutdownFirst {
+		return fmt.Errorf("%s is %s; shut it down first or pass --shutdown-first", dev.Name, strings.ToLower(string(dev.State)))
+	}
+
+	renderer.StartSpinner("Shutting down %s...", dev.Name)
+	if err := mgr.Shutdown(ctx, dev); err != nil {
+		renderer.StopSpinner(false)
+		return fmt.Errorf("failed to shutdown: %w", err)
+	}
+	// During a dry run the shutdown is only planned.
+	if plan == nil {
+		if err := mgr.WaitForState(ctx, dev, device.StateShutdown, time.Minute); err != nil {
+			renderer.StopSpinner(false)
+			return err
+		}
+	}
+	renderer.StopSpinner(true)
+	return nil
+}
+
+// checkNameFree refuses a simulator name that is already taken, since
+// devices are looked up by name.
+func checkNameFree(ctx context.Context, mgr *device.Manager, name string) error {
+	devices, err := mgr.List(ctx, "", false)
+	if err != nil {
+		return fmt.Errorf("failed to list devices: %w", err)
+	}
+	for _, d := range devices {
+		if strings.EqualFold(d.Name, name) {
+			return fmt.Errorf("a device named %q already exists (%s)", d.Name, d.UDID)
+		}
+	}
+	return nil
+}
+
 func devicesTypesCmd() *cobra.Command {
 	var platform string
 
@@ -296,10 +803,25 @@ func resolveDeviceType(ctx context.Context, mgr *device.Manager, input string) (
 	if err != nil {
 		return "", err
 	}
+	return findDeviceType(types, input)
+}
+
+// findDeviceType is resolveDeviceType against an already listed set of
+// device types.
+func findDeviceType(types []device.DeviceTypeInfo, input string) (string, error) {
+	if strings.HasPrefix(input, "com.apple.") {
+		return input, nil
+	}
 
+	// An exact name wins over a partial one ("iPhone 15" vs "iPhone 15 Pro").
 	input = strings.ToLower(input)
 	for _, t := range types {
-		if strings.ToLower(t.Name) == input || strings.Contains(strings.ToLower(t.Name), input) {
+		if strings.ToLower(t.Name) == input {
+			return t.Identifier, nil
+		}
+	}
+	for _, t := range types {
+		if strings.Contains(strings.ToLower(t.Name), input) {
 			return t.Identifier, nil
 		}
 	}
@@ -316,13 +838,23 @@ func resolveRuntime(ctx context.Context, mgr *device.Manager, input string) (str
 	if err != nil {
 		return "", err
 	}
+	return findRuntime(runtimes, input)
+}
+
+// findRuntime is resolveRuntime against an already listed set of runtimes.
+func findRuntime(runtimes []device.RuntimeInfo, input string) (string, error) {
+	if strings.HasPrefix(input, "com.apple.") {
+		return input, nil
+	}
 
 	input = strings.ToLower(input)
 	for _, r := range runtimes {
-		if !r.IsAvailable {
-			continue
+		if r.IsAvailable && strings.ToLower(r.Name) == input {
+			return r.Identifier, nil
 		}
-		if strings.ToLower(r.Name) == input || strings.Contains(strings.ToLower(r.Name), input) {
+	}
+	for _, r := range runtimes {
+		if r.IsAvailable && strings.Contains(strings.ToLower(r.Name), input) {
 			return r.Identifier, nil
 		}
 	}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/run"
+	"github.com/arnavsurve/swiftctl/internal/session"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// logFlags are the log filtering flags shared by run and logs.
+type logFlags struct {
+	level     string
+	subsystem string
+	category  string
+	grep      string
+	json      bool
+}
+
+func (f *logFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.level, "level", "", "Minimum log level (debug, info, default, error, fault)")
+	cmd.Flags().StringVar(&f.subsystem, "subsystem", "", "Only show logs from this subsystem")
+	cmd.Flags().StringVar(&f.category, "category", "", "Only show logs in this category")
+	cmd.Flags().StringVar(&f.grep, "grep", "", "Only show messages matching this regular expression")
+	cmd.Flags().BoolVar(&f.json, "json", false, "Print log entries as JSON lines")
+}
+
+func (f *logFlags) filter() (run.LogFilter, error) {
+	return run.NewLogFilter(f.level, f.subsystem, f.category, f.grep)
+}
+
+func logsCmd() *cobra.Command {
+	var (
+		flags     logFlags
+		last      bool
+		pathsOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs <device> [bundle-id]",
+		Short: "Stream a simulator's logs, or show the last saved run",
+		Long: `Stream the unified log of a booted simulator, optionally limited to one app.
+
+Entries are shown with their time, level and subsystem:category; use the
+filters to narrow them down, or --json to get the parsed entries.
+
+With --last, print the logs saved by the most recent 'swiftctl run --save-logs'
+instead (one file per device, including rotated parts), optionally searched
+with --grep; --path prints the file names to open them elsewhere.`,
+		Example: `  swiftctl logs "iPhone 15 Pro"
+  swiftctl logs "iPhone 15 Pro" com.example.MyApp --level error
+  swiftctl logs "iPhone 15 Pro" com.example.MyApp --subsystem com.example.MyApp --category network
+  swiftctl logs "iPhone 15 Pro" --grep "timeout|retry" --json
+  swiftctl logs --last
+  swiftctl logs --last --grep "===|Fatal error"
+  less $(swiftctl logs --last --path)`,
+		Args: cobra.MaximumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if last {
+				if len(args) > 0 {
+					return fmt.Errorf("--last doesn't take a device or bundle ID")
+				}
+				if flags.level != "" || flags.subsystem != "" || flags.category != "" || flags.json {
+					return fmt.Errorf("saved logs are plain text; only --grep can be used with --last")
+				}
+				return showLastSession(flags.grep, pathsOnly)
+			}
+			if pathsOnly {
+				return fmt.Errorf("--path requires --last")
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("no device given (e.g. swiftctl logs \"iPhone 15 Pro\"), or use --last")
+			}
+
+			filter, err := flags.filter()
+			if err != nil {
+				return err
+			}
+
+			dev, err := device.NewManager().Get(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("device not found: %w", err)
+			}
+			if dev.Type == device.DeviceTypePhysical {
+				return fmt.Errorf("%s is a physical device; its console is streamed by 'swiftctl run'", dev.Name)
+			}
+			if dev.State != device.StateBooted {
+				return fmt.Errorf("%s is not booted (try: swiftctl devices boot %q)", dev.Name, dev.Name)
+			}
+
+			var bundleID string
+			if len(args) == 2 {
+				bundleID = args[1]
+			}
+
+			renderer := ui.NewRenderer()
+			renderer.Dim("Streaming logs from %s (Ctrl+C to stop)...", dev.Name)
+
+			streamer := run.NewLogStreamer(dev, bundleID)
+			streamer.SetFilter(filter)
+			logs, errs := streamer.Stream(ctx)
+
+			for entry := range logs {
+				run.WriteLog(renderer, "", entry, flags.json)
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			return <-errs
+		},
+	}
+
+	flags.register(cmd)
+	cmd.Flags().BoolVar(&last, "last", false, "Show the logs saved by the most recent run --save-logs")
+	cmd.Flags().BoolVar(&pathsOnly, "path", false, "With --last, print the log file paths instead of their contents")
+
+	return cmd
+}
+
+// showLastSession prints the most recent session's log files, keeping only
+// lines that match grep when it is set.
+func showLastSession(grep string, pathsOnly bool) error {
+	root := "."
+	if proj := currentProject(); proj != nil {
+		root = proj.Root()
+	}
+
+	files, err := session.Latest(root)
+	if err != nil {
+		return err
+	}
+
+	if pathsOnly {
+		for _, f := range files {
+			fmt.Println(f)
+		}
+		return nil
+	}
+
+	var re *regexp.Regexp
+	if grep != "" {
+		if re, err = regexp.Compile(grep); err != nil {
+			return fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+
+	renderer := ui.NewRenderer()
+	for _, f := range files {
+		renderer.Info("%s", f)
+		for _, part := range session.Parts(f) {
+			if err := printLines(part, re); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func printLines(path string, re *regexp.Regexp) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if re == nil || re.MatchString(scanner.Text()) {
+			fmt.Println(scanner.Text())
+		}
+	}
+	return scanner.Err()
+}
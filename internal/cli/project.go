@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/arnavsurve/swiftctl/internal/cache"
 	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/run"
 	"github.com/arnavsurve/swiftctl/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -39,10 +42,30 @@ func projectInfoCmd() *cobra.Command {
 				return fmt.Errorf("no project found: %w", err)
 			}
 
+			// Metadata from the last build, when there is one.
+			var app *run.AppInfo
+			scheme := info.Name
+			if len(info.Schemes) > 0 {
+				scheme = info.Schemes[0]
+			}
+			for _, mode := range []string{cache.ModeLocal, cache.ModeCache, cache.ModeGlobal} {
+				derivedData, err := cache.DerivedDataPath(mode, info)
+				if err != nil {
+					continue
+				}
+				if appPath, err := run.LatestApp(derivedData, info.Name, scheme); err == nil {
+					app, _ = run.ReadAppInfo(appPath)
+					break
+				}
+			}
+
 			if jsonOut {
 				enc := json.NewEncoder(os.Stdout)
 				enc.SetIndent("", "  ")
-				return enc.Encode(info)
+				return enc.Encode(struct {
+					*project.ProjectInfo
+					App *run.AppInfo `json:"app,omitempty"`
+				}{info, app})
 			}
 
 			renderer := ui.NewRenderer()
@@ -78,6 +101,22 @@ func projectInfoCmd() *cobra.Command {
 				}
 			}
 
+			if app != nil {
+				renderer.Info("")
+				renderer.Info("Built app: %s", app.Path)
+				renderer.Info("  Bundle ID:  %s", app.BundleID)
+				renderer.Info("  Executable: %s", app.Executable)
+				if app.Version != "" {
+					renderer.Info("  Version:    %s", app.Version)
+				}
+				if app.MinimumOSVersion != "" {
+					renderer.Info("  Minimum OS: %s", app.MinimumOSVersion)
+				}
+				if names := app.DeviceFamilyNames(); len(names) > 0 {
+					renderer.Info("  Devices:    %s", strings.Join(names, ", "))
+				}
+			}
+
 			return nil
 		},
 	}
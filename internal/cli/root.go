@@ -2,14 +2,26 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	verbose bool
-	rootCmd *cobra.Command
+	verbose    bool
+	recordDir  string
+	replayDir  string
+	dryRun     bool
+	planFormat string
+	ciMode     bool
+	profile    string
+	plan       *process.Plan
+	rootCmd    *cobra.Command
 )
 
 func init() {
@@ -25,12 +37,24 @@ Common workflows:
   swiftctl build            Just build the project`,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			process.SetGlobalVerbose(verbose)
+			setupCI()
+			if err := setupDryRun(); err != nil {
+				return err
+			}
+			return setupExecutor()
 		},
 	}
 
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show underlying commands")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record external tool invocations into a fixture directory")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Replay external tool invocations from a fixture directory")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Use a named profile from .swiftctl.yaml")
+	rootCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "CI output: no spinner, inline annotations, job summary (auto-detected on GitHub Actions and GitLab CI)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print the commands that would run without executing them")
+	rootCmd.PersistentFlags().StringVar(&planFormat, "plan-format", "text", "Format of the --dry-run plan (text or json)")
 }
 
 func Execute(ctx context.Context, version string) error {
@@ -38,10 +62,105 @@ func Execute(ctx context.Context, version string) error {
 
 	rootCmd.AddCommand(devicesCmd())
 	rootCmd.AddCommand(buildCmd())
+	rootCmd.AddCommand(cleanCmd())
+	rootCmd.AddCommand(cacheCmd())
+	rootCmd.AddCommand(configCmd())
+	rootCmd.AddCommand(debugCmd())
+	rootCmd.AddCommand(logsCmd())
 	rootCmd.AddCommand(projectCmd())
 	rootCmd.AddCommand(runCmd())
+	rootCmd.AddCommand(symbolicateCmd())
+	rootCmd.AddCommand(testCmd())
 
-	return rootCmd.ExecuteContext(ctx)
+	err := rootCmd.ExecuteContext(ctx)
+
+	// Printed here rather than in a post-run hook, which cobra skips when
+	// the command fails: the commands collected up to a failure are still
+	// worth seeing.
+	if plan != nil {
+		if perr := printPlan(plan); err == nil {
+			err = perr
+		}
+	}
+	return err
 }
 
 func Verbose() bool { return verbose }
+
+// setupExecutor installs the record or replay executor requested by global flags.
+func setupExecutor() error {
+	switch {
+	case recordDir != "":
+		rec, err := process.NewRecordingExecutor(process.ExecExecutor{}, recordDir)
+		if err != nil {
+			return err
+		}
+		process.SetGlobalExecutor(rec)
+	case replayDir != "":
+		rep, err := process.NewReplayExecutor(replayDir)
+		if err != nil {
+			return fmt.Errorf("replay: %w", err)
+		}
+		process.SetGlobalExecutor(rep)
+	}
+	return nil
+}
+
+// setupCI enables CI output when detected from the environment or forced with --ci.
+func setupCI() {
+	provider := ui.DetectCI()
+	if ciMode && provider == ui.CINone {
+		provider = ui.CIGeneric
+	}
+	ui.SetGlobalCI(provider)
+}
+
+// setupDryRun starts collecting mutating commands instead of running them.
+func setupDryRun() error {
+	if !dryRun {
+		return nil
+	}
+	switch planFormat {
+	case "text", "json":
+		plan = &process.Plan{}
+		process.SetGlobalPlan(plan)
+		return nil
+	default:
+		return fmt.Errorf("invalid --plan-format: %s (valid: text, json)", planFormat)
+	}
+}
+
+func printPlan(p *process.Plan) error {
+	commands := p.Commands()
+
+	if planFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Commands []process.Command `json:"commands"`
+		}{Commands: append([]process.Command{}, commands...)})
+	}
+
+	if len(commands) == 0 {
+		fmt.Println("Dry run: no commands would be executed")
+		return nil
+	}
+
+	fmt.Printf("Dry run: %d command(s) would be executed\n", len(commands))
+	for i, c := range commands {
+		fmt.Printf("%3d. %s\n", i+1, shellQuote(c))
+	}
+	return nil
+}
+
+// shellQuote formats a command so it can be pasted into a shell.
+func shellQuote(c process.Command) string {
+	parts := append([]string{}, c.Env...)
+	for _, s := range append([]string{c.Name}, c.Args...) {
+		if s == "" || strings.ContainsAny(s, " \t\"'$`\\*?;&|<>()") {
+			s = "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
-	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/cache"
+	"github.com/arnavsurve/swiftctl/internal/config"
 	"github.com/arnavsurve/swiftctl/internal/device"
 	"github.com/arnavsurve/swiftctl/internal/project"
 	"github.com/arnavsurve/swiftctl/internal/run"
@@ -15,60 +17,132 @@ func runCmd() *cobra.Command {
 	var (
 		scheme        string
 		configuration string
-		deviceName    string
+		deviceNames   []string
+		devices       []string
 		watch         bool
 		launchArgs    []string
+		env           []string
+		waitDebugger  bool
+		stdoutPath    string
+		stderrPath    string
+		consolePTY    bool
+		derivedData   string
+		logs          logFlags
+		saveLogs      bool
+		relaunchCrash bool
+		headless      bool
+		bootTimeout   time.Duration
 	)
 
 	cmd := &cobra.Command{
-		Use:   "run <platform>",
-		Short: "Build, deploy, and run on simulator",
+		Use:   "run [platform]",
+		Short: "Build, deploy, and run on a simulator, device or this Mac",
 		Long: `Build the project, boot a simulator, install the app, launch it, and stream logs.
 
-Use -w/--watch to automatically rebuild and relaunch when source files change.`,
+Connected physical devices (listed by 'swiftctl devices list') can be targeted
+with -d; their console output is streamed instead of the simulator log.
+
+Repeat -d (or pass --devices) to build once and deploy to several devices
+concurrently. Their logs are interleaved, each line prefixed with the device
+name; a device that fails to deploy is reported without stopping the others.
+
+With macos the app runs on this Mac and its stdout/stderr are streamed
+directly. Swift packages always run this way: the executable product named
+with -s (default: the first one) is built with swift build and run.
+
+Use -w/--watch to automatically rebuild and relaunch when source files change.
+
+A simulator that isn't running is booted and waited on until it is ready to
+install apps (up to --boot-timeout); --headless keeps the Simulator app closed.
+
+On simulators the launched app is monitored: if it crashes, the newest .ips
+report for it is found and summarized (exception, fatal error message and the
+crashed thread's backtrace). Without -w, run then exits with an error; with
+-w it waits for the next change, or relaunches right away with
+--relaunch-on-crash.
+
+Logs are shown with their time, level and subsystem:category. Narrow them
+with --level, --subsystem, --category and --grep, or print the parsed
+entries with --json. Console output (physical devices and --console-pty) is
+only filtered by --grep.
+
+With --save-logs, the build output, app logs and markers for launches and
+watch-mode rebuilds are also written to .swiftctl/logs/<timestamp>-<device>-<udid>.log
+(rotated at 10 MB); reopen the latest with 'swiftctl logs --last'.
+
+The platform can be omitted when .swiftctl.yaml (or the --profile in use) sets one.`,
 		Example: `  swiftctl run ios
   swiftctl run ios -w
   swiftctl run ios -s MyScheme -d "iPhone 15 Pro"
+  swiftctl run ios -d "My iPhone"
+  swiftctl run ios -d "iPhone 15" -d "iPad Pro 13-inch (M4)" -w
+  swiftctl run ios --devices "iPhone 15,iPhone SE (3rd generation)"
   swiftctl run ios -c release
-  swiftctl run ios --args="-verbose,-debug"`,
-		Args:      cobra.ExactArgs(1),
-		ValidArgs: []string{"ios", "watchos", "tvos", "visionos"},
+  swiftctl run ios --args="-verbose,-debug"
+  swiftctl run ios -e API_URL=http://localhost:8080 -e DEBUG=1
+  swiftctl run ios --console-pty
+  swiftctl run ios --level error --subsystem com.example.MyApp
+  swiftctl run ios --grep "network|timeout" --json
+  swiftctl run ios -w --save-logs
+  swiftctl run ios -w --relaunch-on-crash
+  swiftctl run ios --wait-for-debugger
+  swiftctl run ios --headless --boot-timeout 5m
+  swiftctl run macos -w
+  swiftctl run -s mytool --args="--port,8080"
+  swiftctl run --profile qa`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"ios", "watchos", "tvos", "visionos", "macos"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			renderer := ui.NewRenderer()
 
-			platform := device.Platform(args[0])
-
-			switch platform {
-			case device.PlatformIOS, device.PlatformWatchOS, device.PlatformTVOS, device.PlatformVisionOS:
-				// Valid
-			case device.PlatformMacOS:
-				return fmt.Errorf("use 'swiftctl build' for macOS, then run the binary directly")
-			default:
-				return fmt.Errorf("unknown platform: %s (valid: ios, watchos, tvos, visionos)", args[0])
-			}
-
 			detector := project.NewDetector()
 			proj, err := detector.Detect(".")
 			if err != nil {
 				return fmt.Errorf("no project found: %w", err)
 			}
 
+			settings, err := loadConfig(cmd, proj)
+			if err != nil {
+				return err
+			}
+			platform, err := runPlatform(cmd, settings, proj, args)
+			if err != nil {
+				return err
+			}
+
+			logFilter, err := logs.filter()
+			if err != nil {
+				return err
+			}
+
 			renderer.Info("Project: %s (%s)", proj.Name, proj.Type)
+			if settings.Profile != "" {
+				renderer.Info("Profile: %s", settings.Profile)
+			}
 
-			cfg := run.Config{
-				Scheme:     scheme,
-				Platform:   platform,
-				DeviceName: deviceName,
-				Watch:      watch,
-				LaunchArgs: launchArgs,
+			derivedDataPath, err := cache.DerivedDataPath(settings.DerivedData, proj)
+			if err != nil {
+				return err
 			}
 
-			switch configuration {
-			case "release", "Release":
-				cfg.Configuration = build.ConfigRelease
-			default:
-				cfg.Configuration = build.ConfigDebug
+			cfg := run.Config{
+				Scheme:        settings.Scheme,
+				Configuration: buildConfiguration(settings.Configuration),
+				Platform:      platform,
+				Devices:       settings.DeviceNames(),
+				Watch:         watch,
+				Launch:        settings.LaunchOptions(),
+				ExtraArgs:     settings.XcodebuildArgs,
+				WatchPatterns: settings.Watch,
+				DerivedData:   derivedDataPath,
+				LogFilter:     logFilter,
+				LogJSON:       logs.json,
+				SaveLogs:      saveLogs,
+				Headless:      headless,
+				BootTimeout:   bootTimeout,
+
+				RelaunchOnCrash: relaunchCrash,
 			}
 
 			runner := run.NewRunner(proj)
@@ -78,9 +152,52 @@ Use -w/--watch to automatically rebuild and relaunch when source files change.`,
 
 	cmd.Flags().StringVarP(&scheme, "scheme", "s", "", "Scheme to build (default: first available)")
 	cmd.Flags().StringVarP(&configuration, "configuration", "c", "debug", "Build configuration (debug/release)")
-	cmd.Flags().StringVarP(&deviceName, "device", "d", "", "Target device name or UDID")
+	cmd.Flags().StringArrayVarP(&deviceNames, "device", "d", nil, "Target device name or UDID; repeat to run on several")
+	cmd.Flags().StringSliceVar(&devices, "devices", nil, "Comma-separated devices to run on")
+	cmd.MarkFlagsMutuallyExclusive("device", "devices")
 	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Watch for file changes and rebuild")
 	cmd.Flags().StringSliceVar(&launchArgs, "args", nil, "Arguments to pass to the launched app")
+	cmd.Flags().StringArrayVarP(&env, "env", "e", nil, "Environment variable for the app as KEY=VALUE; repeatable")
+	cmd.Flags().BoolVar(&waitDebugger, "wait-for-debugger", false, "Suspend the app at launch until a debugger attaches")
+	cmd.Flags().StringVar(&stdoutPath, "stdout", "", "Write the app's stdout to a file (simulators and this Mac)")
+	cmd.Flags().StringVar(&stderrPath, "stderr", "", "Write the app's stderr to a file (simulators and this Mac)")
+	cmd.Flags().BoolVar(&consolePTY, "console-pty", false, "Attach the app's stdio through a pty instead of streaming the log (simulators)")
+	cmd.Flags().StringVar(&derivedData, "derived-data", "", derivedDataUsage)
+	logs.register(cmd)
+	cmd.Flags().BoolVar(&saveLogs, "save-logs", false, "Also write build output and app logs to .swiftctl/logs")
+	cmd.Flags().BoolVar(&relaunchCrash, "relaunch-on-crash", false, "In watch mode, relaunch the app after it crashes")
+	cmd.Flags().BoolVar(&headless, "headless", false, "Boot simulators without opening the Simulator app (for CI)")
+	cmd.Flags().DurationVar(&bootTimeout, "boot-timeout", device.DefaultBootTimeout, "How long to wait for a simulator to finish booting")
 
 	return cmd
 }
+
+// runPlatform resolves the platform for run and debug from the optional
+// argument or the config. Swift packages default to (and only run on) macos.
+func runPlatform(cmd *cobra.Command, settings *config.Resolved, proj *project.ProjectInfo, args []string) (device.Platform, error) {
+	if len(args) == 1 {
+		if err := settings.Override("platform", args[0], "argument"); err != nil {
+			return "", err
+		}
+	}
+	if settings.Platform == "" && proj.Type == project.ProjectTypeSPM {
+		settings.Platform = string(device.PlatformMacOS)
+	}
+	if settings.Platform == "" {
+		return "", fmt.Errorf("no platform given (pass one, e.g. 'swiftctl %s ios', or set platform in %s)", cmd.Name(), config.FileName)
+	}
+
+	platform := device.Platform(settings.Platform)
+
+	switch platform {
+	case device.PlatformIOS, device.PlatformWatchOS, device.PlatformTVOS, device.PlatformVisionOS:
+		if proj.Type == project.ProjectTypeSPM {
+			return "", fmt.Errorf("swift packages run on this Mac only (use 'swiftctl %s macos')", cmd.Name())
+		}
+	case device.PlatformMacOS:
+		// Runs on this Mac
+	default:
+		return "", fmt.Errorf("unknown platform: %s (valid: ios, watchos, tvos, visionos, macos)", settings.Platform)
+	}
+	return platform, nil
+}
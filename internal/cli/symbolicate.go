@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/cache"
+	"github.com/arnavsurve/swiftctl/internal/crash"
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/run"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func symbolicateCmd() *cobra.Command {
+	var (
+		scheme      string
+		config      string
+		derivedData string
+		dsyms       []string
+		jsonOut     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "symbolicate <crash.ips>",
+		Short: "Symbolicate a crash report with the project's dSYMs",
+		Long: `Resolve the frames of an .ips crash report to symbols and file:line.
+
+The binaries loaded in the crashed process are matched by UUID against the
+dSYMs and executables in the project's build products (found through the
+scheme's build settings), plus any passed with --dsym. Matching images are
+resolved with a single atos call each; the rest are left as they are.`,
+		Example: `  swiftctl symbolicate ~/Library/Logs/DiagnosticReports/MyApp-2024-05-01-101500.ips
+  swiftctl symbolicate crash.ips --dsym build/MyApp.app.dSYM
+  swiftctl symbolicate crash.ips --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			renderer := ui.NewRenderer()
+
+			rep, err := crash.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			dirs := dsyms
+			// Outside a project only --dsym is searched.
+			if proj, err := project.NewDetector().Detect("."); err == nil {
+				productDirs, err := symbolDirs(ctx, cmd, proj)
+				if err != nil {
+					renderer.Warning("Build products not found: %v", err)
+				}
+				dirs = append(dirs, productDirs...)
+			}
+
+			index := crash.Index(crash.Binaries(dirs...))
+			matched := 0
+			for _, img := range rep.Images {
+				if _, ok := index[img.UUID]; ok {
+					matched++
+				}
+			}
+			if matched == 0 {
+				renderer.Warning("No dSYM or binary matches the images in %s (pass one with --dsym)", filepath.Base(rep.Path))
+			} else {
+				n, err := crash.NewSymbolicator().Symbolicate(ctx, rep, index)
+				if err != nil {
+					return fmt.Errorf("symbolication failed: %w", err)
+				}
+				renderer.Dim("Symbolicated %d frames from %d images", n, matched)
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(rep)
+			}
+			run.PrintCrash(renderer, rep, 0)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&scheme, "scheme", "s", "", "Scheme whose build products to search")
+	cmd.Flags().StringVarP(&config, "configuration", "c", "debug", "Build configuration (debug/release)")
+	cmd.Flags().StringVar(&derivedData, "derived-data", "", derivedDataUsage)
+	cmd.Flags().StringArrayVar(&dsyms, "dsym", nil, "dSYM bundle, binary or directory to search; repeatable")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print the symbolicated report as JSON")
+
+	return cmd
+}
+
+// symbolDirs returns the build products directories of proj: every
+// configuration and SDK next to the scheme's TARGET_BUILD_DIR, or the
+// .build directories of a package.
+func symbolDirs(ctx context.Context, cmd *cobra.Command, proj *project.ProjectInfo) ([]string, error) {
+	if proj.Type == project.ProjectTypeSPM {
+		return filepath.Glob(filepath.Join(proj.Root(), ".build", "*", "*"))
+	}
+
+	settings, err := loadConfig(cmd, proj)
+	if err != nil {
+		return nil, err
+	}
+	derivedDataPath, err := cache.DerivedDataPath(settings.DerivedData, proj)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := build.Config{
+		Scheme:        settings.Scheme,
+		Configuration: buildConfiguration(settings.Configuration),
+		DerivedData:   derivedDataPath,
+		ExtraArgs:     settings.XcodebuildArgs,
+	}
+	if settings.Platform != "" {
+		cfg.Platform = device.Platform(settings.Platform)
+	} else if len(proj.Platforms) > 0 {
+		cfg.Platform = proj.Platforms[0]
+	}
+
+	buildSettings, err := build.NewBuilder(proj).Settings(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build/Products/<Configuration>-<sdk>; the crash may come from a
+	// build for another SDK or configuration than the default one.
+	return filepath.Glob(filepath.Join(filepath.Dir(buildSettings.TargetBuildDir), "*"))
+}
@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/cache"
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/report"
+	"github.com/arnavsurve/swiftctl/internal/run"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func testCmd() *cobra.Command {
+	var (
+		scheme      string
+		config      string
+		platform    string
+		deviceName  string
+		destination string
+		only        []string
+		skip        []string
+		reports     []string
+		derivedData string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "Run tests",
+		Long: `Build and run the project's tests with live per-test reporting.
+
+Xcode projects run 'xcodebuild test' against a simulator (picked the same way
+as 'swiftctl run'); Swift packages run 'swift test'.`,
+		Example: `  swiftctl test
+  swiftctl test -s MyAppTests -d "iPhone 15 Pro"
+  swiftctl test --only MyAppTests/LoginTests
+  swiftctl test --skip MyAppTests/SlowTests/testUpload
+  swiftctl test --report junit=build/junit.xml`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			renderer := ui.NewRenderer()
+
+			specs, err := parseReportSpecs(reports)
+			if err != nil {
+				return err
+			}
+
+			detector := project.NewDetector()
+			proj, err := detector.Detect(".")
+			if err != nil {
+				return fmt.Errorf("no project found: %w", err)
+			}
+
+			settings, err := loadConfig(cmd, proj)
+			if err != nil {
+				return err
+			}
+
+			derivedDataPath, err := cache.DerivedDataPath(settings.DerivedData, proj)
+			if err != nil {
+				return err
+			}
+
+			cfg := build.TestConfig{
+				Config: build.Config{
+					Scheme:        settings.Scheme,
+					Configuration: buildConfiguration(settings.Configuration),
+					Destination:   destination,
+					DerivedData:   derivedDataPath,
+					ExtraArgs:     settings.XcodebuildArgs,
+				},
+				Only: only,
+				Skip: skip,
+			}
+
+			if settings.Platform != "" {
+				cfg.Platform = device.Platform(settings.Platform)
+			} else if len(proj.Platforms) > 0 {
+				cfg.Platform = proj.Platforms[0]
+			}
+
+			if proj.Type != project.ProjectTypeSPM && cfg.Destination == "" && cfg.Platform != device.PlatformMacOS {
+				names := settings.DeviceNames()
+				if len(names) > 1 {
+					return fmt.Errorf("tests run on one device at a time (got %d; pick one with -d)", len(names))
+				}
+				dev, err := run.NewRunner(proj).ResolveDevice(ctx, run.Config{
+					Devices:  names,
+					Platform: cfg.Platform,
+				})
+				if err != nil {
+					return err
+				}
+				cfg.Destination = dev.Destination()
+				renderer.Info("Device: %s (%s)", dev.Name, dev.OSVersion)
+			}
+
+			schemeName := cfg.Scheme
+			if schemeName == "" && len(proj.Schemes) > 0 {
+				schemeName = proj.Schemes[0]
+			}
+			if schemeName == "" {
+				schemeName = proj.Name
+			}
+
+			renderer.StartGroup("Test %s", schemeName)
+			renderer.StartSpinner("Building %s for testing...", schemeName)
+
+			events := make(chan build.TestEvent, 100)
+			done := make(chan struct{})
+
+			go func() {
+				for ev := range events {
+					name := testName(ev.Suite, ev.Name)
+					switch ev.Type {
+					case build.TestStarted:
+						renderer.StopSpinner(true)
+						renderer.StartSpinner("%s", name)
+
+					case build.TestPassed:
+						renderer.StopSpinner(true)
+						renderer.Success("%s (%s)", name, ui.Duration(ev.Duration))
+
+					case build.TestFailed:
+						renderer.StopSpinner(false)
+						renderer.Error("%s (%s)", name, ui.Duration(ev.Duration))
+						if ev.Message != "" {
+							renderer.Info("  %s:%d: %s", filepath.Base(ev.File), ev.Line, ev.Message)
+						}
+
+					case build.TestSkipped:
+						renderer.StopSpinner(true)
+						renderer.Dim("- %s (skipped)", name)
+					}
+				}
+				close(done)
+			}()
+
+			builder := build.NewBuilder(proj)
+			result, err := builder.Test(ctx, cfg, events)
+			close(events)
+			<-done
+
+			renderer.StopSpinner(result != nil && result.Success)
+			renderer.EndGroup()
+
+			if err != nil {
+				return err
+			}
+
+			if result.Build != nil {
+				annotateDiagnostics(renderer, result.Build)
+			}
+			for _, c := range result.Cases {
+				for _, f := range c.Failures {
+					renderer.Annotate("error", f.File, f.Line, 0, testName(c.Suite, c.Name)+": "+f.Message)
+				}
+			}
+			printTestSummary(renderer, result)
+
+			// A dry run ran nothing, so there is nothing to summarize.
+			if plan == nil {
+				if err := renderer.WriteSummary(testSummary(schemeName, result)); err != nil {
+					renderer.Warning("Job summary: %v", err)
+				}
+				writeReports(renderer, specs, report.Report{Build: result.Build, Tests: result, Root: proj.Root()})
+			}
+
+			if !result.Success {
+				return fmt.Errorf("tests failed")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&scheme, "scheme", "s", "", "Scheme to test")
+	cmd.Flags().StringVarP(&config, "configuration", "c", "debug", "Build configuration (debug/release)")
+	cmd.Flags().StringVarP(&platform, "platform", "p", "", "Target platform (ios, macos, etc.)")
+	cmd.Flags().StringVarP(&deviceName, "device", "d", "", "Target device name or UDID")
+	cmd.Flags().StringVar(&destination, "destination", "", "Test destination (xcodebuild format)")
+	cmd.Flags().StringSliceVar(&only, "only", nil, "Only run these tests (Target[/Class[/method]])")
+	cmd.Flags().StringSliceVar(&skip, "skip", nil, "Skip these tests (Target[/Class[/method]])")
+	cmd.Flags().StringArrayVar(&reports, "report", nil, "Write a report as format=path (junit, json, sarif); repeatable")
+	cmd.Flags().StringVar(&derivedData, "derived-data", "", derivedDataUsage)
+
+	return cmd
+}
+
+func printTestSummary(renderer *ui.Renderer, result *build.TestResult) {
+	passed, failed, skipped := result.Counts()
+
+	if len(result.Build.Errors) > 0 {
+		renderer.Error("Build failed with %d error(s)", len(result.Build.Errors))
+		for i, e := range result.Build.Errors {
+			if i >= 5 {
+				renderer.Info("... and %d more errors", len(result.Build.Errors)-5)
+				break
+			}
+			renderer.Info("  %s:%d: %s", filepath.Base(e.File), e.Line, e.Message)
+		}
+		return
+	}
+
+	slowest := make([]build.TestCase, 0, len(result.Cases))
+	for _, c := range result.Cases {
+		if c.Status != build.StatusSkipped {
+			slowest = append(slowest, c)
+		}
+	}
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+	if len(slowest) > 5 {
+		slowest = slowest[:5]
+	}
+	if len(slowest) > 1 {
+		renderer.Info("")
+		renderer.Info("Slowest tests:")
+		for _, c := range slowest {
+			renderer.Info("  %8s  %s", ui.Duration(c.Duration), testName(c.Suite, c.Name))
+		}
+	}
+
+	if failed > 0 {
+		renderer.Info("")
+		renderer.Info("Failures:")
+		for _, c := range result.Cases {
+			if c.Status != build.StatusFailed {
+				continue
+			}
+			renderer.Error("%s", testName(c.Suite, c.Name))
+			for _, f := range c.Failures {
+				renderer.Info("  %s:%d: %s", f.File, f.Line, f.Message)
+			}
+		}
+	}
+
+	renderer.Info("")
+	summary := fmt.Sprintf("%d passed, %d failed, %d skipped in %.1fs", passed, failed, skipped, result.Duration.Seconds())
+	if result.Success {
+		renderer.Success("%s", summary)
+	} else {
+		renderer.Error("%s", summary)
+	}
+}
+
+func testName(suite, name string) string {
+	if suite == "" {
+		return name
+	}
+	return suite + "." + name
+}
+
+// testSummary renders a markdown job summary for a test run.
+func testSummary(scheme string, result *build.TestResult) string {
+	var b strings.Builder
+
+	status := "✅ passed"
+	if !result.Success {
+		status = "❌ failed"
+	}
+	passed, failed, skipped := result.Counts()
+	fmt.Fprintf(&b, "### swiftctl test %s: %s\n\n", scheme, status)
+	b.WriteString("| Duration | Passed | Failed | Skipped |\n|---|---|---|---|\n")
+	fmt.Fprintf(&b, "| %.1fs | %d | %d | %d |\n\n", result.Duration.Seconds(), passed, failed, skipped)
+
+	for _, c := range result.Cases {
+		if c.Status == build.StatusFailed {
+			fmt.Fprintf(&b, "- `%s`\n", testName(c.Suite, c.Name))
+		}
+	}
+	if failed > 0 {
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
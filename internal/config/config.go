@@ -0,0 +1,337 @@
+// Package config loads project defaults from .swiftctl.yaml.
+//
+// The file lives next to the detected project and holds top-level defaults
+// plus named profiles:
+//
+//	scheme: MyApp
+//	configuration: debug
+//	platform: ios
+//	device: iPhone 17 Pro      # or devices: [iPhone 17 Pro, iPad Air 11-inch (M3)]
+//	args: [-verbose]
+//	env:
+//	  API_URL: https://staging.example.com
+//	xcodebuild_args: [-skipPackagePluginValidation]
+//	watch: [.swift, "*.json"]
+//	derived_data: local
+//	console_pty: false         # also wait_for_debugger, stdout, stderr
+//	source_map:                # for debug: build-time source paths -> paths in this checkout
+//	  /Users/ci/work/MyApp: .
+//	profiles:
+//	  qa:
+//	    configuration: release
+//	    env:
+//	      API_URL: https://qa.example.com
+//
+// A profile overrides the defaults it sets; env and source_map are merged
+// key by key.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the config file looked up in the project root.
+const FileName = ".swiftctl.yaml"
+
+// Settings are the values a config file, profile or flag can set. Empty
+// fields are unset.
+type Settings struct {
+	Scheme         string            `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	Configuration  string            `yaml:"configuration,omitempty" json:"configuration,omitempty"`
+	Platform       string            `yaml:"platform,omitempty" json:"platform,omitempty"`
+	Device         string            `yaml:"device,omitempty" json:"device,omitempty"`
+	Devices        []string          `yaml:"devices,omitempty" json:"devices,omitempty"` // replaces device to run on several at once
+	Args           []string          `yaml:"args,omitempty" json:"args,omitempty"`
+	Env            map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	XcodebuildArgs []string          `yaml:"xcodebuild_args,omitempty" json:"xcodebuild_args,omitempty"`
+	Watch          []string          `yaml:"watch,omitempty" json:"watch,omitempty"`
+	DerivedData    string            `yaml:"derived_data,omitempty" json:"derived_data,omitempty"`
+
+	// Launch options for run; see device.LaunchOptions.
+	WaitForDebugger *bool  `yaml:"wait_for_debugger,omitempty" json:"wait_for_debugger,omitempty"`
+	Stdout          string `yaml:"stdout,omitempty" json:"stdout,omitempty"`
+	Stderr          string `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+	ConsolePTY      *bool  `yaml:"console_pty,omitempty" json:"console_pty,omitempty"`
+
+	// SourceMap maps source paths recorded at build time to local ones
+	// (relative to the project root) for lldb.
+	SourceMap map[string]string `yaml:"source_map,omitempty" json:"source_map,omitempty"`
+}
+
+// File is the parsed contents of .swiftctl.yaml.
+type File struct {
+	Settings `yaml:",inline"`
+	Profiles map[string]Settings `yaml:"profiles,omitempty"`
+}
+
+// Keys lists the setting names in display order.
+var Keys = []string{
+	"scheme", "configuration", "platform", "device", "devices", "args", "env", "xcodebuild_args", "watch", "derived_data",
+	"wait_for_debugger", "stdout", "stderr", "console_pty", "source_map",
+}
+
+// Load reads FileName from dir. A missing file is not an error; it returns
+// an empty File and an empty path.
+func Load(dir string) (*File, string, error) {
+	path := filepath.Join(dir, FileName)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+	return &f, path, nil
+}
+
+// Resolved is the effective configuration and where each value came from.
+type Resolved struct {
+	Settings
+	Profile string
+	Sources map[string]string // key -> "default", FileName, "profile <name>" or the overriding flag
+}
+
+// Resolve merges the file's defaults with the named profile (if any) on top
+// of swiftctl's built-in defaults.
+func (f *File) Resolve(path, profile string) (*Resolved, error) {
+	r := &Resolved{
+		Settings: Settings{Configuration: "debug", DerivedData: "global"},
+		Profile:  profile,
+		Sources:  map[string]string{"configuration": "default", "derived_data": "default"},
+	}
+
+	r.apply(f.Settings, FileName)
+
+	if profile != "" {
+		p, ok := f.Profiles[profile]
+		if !ok {
+			if path == "" {
+				return nil, fmt.Errorf("profile %q: no %s found", profile, FileName)
+			}
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(f.ProfileNames(), ", "))
+		}
+		r.apply(p, "profile "+profile)
+	}
+
+	return r, nil
+}
+
+// ProfileNames returns the defined profiles, sorted.
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *Resolved) apply(s Settings, source string) {
+	set := func(key string, ok bool) {
+		if ok {
+			r.Sources[key] = source
+		}
+	}
+
+	if s.Scheme != "" {
+		r.Scheme = s.Scheme
+	}
+	set("scheme", s.Scheme != "")
+	if s.Configuration != "" {
+		r.Configuration = s.Configuration
+	}
+	set("configuration", s.Configuration != "")
+	if s.Platform != "" {
+		r.Platform = s.Platform
+	}
+	set("platform", s.Platform != "")
+	// device and devices are one setting: whichever is set last wins.
+	if s.Device != "" {
+		r.Device, r.Devices = s.Device, nil
+		delete(r.Sources, "devices")
+	}
+	set("device", s.Device != "")
+	if s.Devices != nil {
+		r.Device, r.Devices = "", s.Devices
+		delete(r.Sources, "device")
+	}
+	set("devices", s.Devices != nil)
+	if s.Args != nil {
+		r.Args = s.Args
+	}
+	set("args", s.Args != nil)
+	if s.XcodebuildArgs != nil {
+		r.XcodebuildArgs = s.XcodebuildArgs
+	}
+	set("xcodebuild_args", s.XcodebuildArgs != nil)
+	if s.Watch != nil {
+		r.Watch = s.Watch
+	}
+	set("watch", s.Watch != nil)
+	if s.DerivedData != "" {
+		r.DerivedData = s.DerivedData
+	}
+	set("derived_data", s.DerivedData != "")
+	if s.WaitForDebugger != nil {
+		r.WaitForDebugger = s.WaitForDebugger
+	}
+	set("wait_for_debugger", s.WaitForDebugger != nil)
+	if s.Stdout != "" {
+		r.Stdout = s.Stdout
+	}
+	set("stdout", s.Stdout != "")
+	if s.Stderr != "" {
+		r.Stderr = s.Stderr
+	}
+	set("stderr", s.Stderr != "")
+	if s.ConsolePTY != nil {
+		r.ConsolePTY = s.ConsolePTY
+	}
+	set("console_pty", s.ConsolePTY != nil)
+
+	if len(s.Env) > 0 {
+		env := make(map[string]string, len(r.Env)+len(s.Env))
+		for k, v := range r.Env {
+			env[k] = v
+		}
+		for k, v := range s.Env {
+			env[k] = v
+		}
+		r.Env = env
+		r.Sources["env"] = source
+	}
+
+	if len(s.SourceMap) > 0 {
+		sourceMap := make(map[string]string, len(r.SourceMap)+len(s.SourceMap))
+		for k, v := range r.SourceMap {
+			sourceMap[k] = v
+		}
+		for k, v := range s.SourceMap {
+			sourceMap[k] = v
+		}
+		r.SourceMap = sourceMap
+		r.Sources["source_map"] = source
+	}
+}
+
+// Override sets one value from the command line, recording source (e.g.
+// "flag --scheme"). value is a string for scalar keys, a []string for lists,
+// and KEY=VALUE pairs for env.
+func (r *Resolved) Override(key string, value any, source string) error {
+	var s Settings
+	switch key {
+	case "scheme":
+		s.Scheme, _ = value.(string)
+	case "configuration":
+		s.Configuration, _ = value.(string)
+	case "platform":
+		s.Platform, _ = value.(string)
+	case "device":
+		// A repeated flag gives several devices.
+		switch v := value.(type) {
+		case string:
+			s.Device = v
+		case []string:
+			if len(v) == 1 {
+				s.Device = v[0]
+			} else {
+				s.Devices = v
+			}
+		}
+	case "devices":
+		s.Devices, _ = value.([]string)
+	case "derived_data":
+		s.DerivedData, _ = value.(string)
+	case "args":
+		s.Args, _ = value.([]string)
+		if s.Args == nil {
+			s.Args = []string{}
+		}
+	case "xcodebuild_args":
+		s.XcodebuildArgs, _ = value.([]string)
+	case "watch":
+		s.Watch, _ = value.([]string)
+	case "stdout":
+		s.Stdout, _ = value.(string)
+	case "stderr":
+		s.Stderr, _ = value.(string)
+	case "wait_for_debugger", "console_pty":
+		str, _ := value.(string)
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return fmt.Errorf("invalid %s value %q", key, str)
+		}
+		if key == "wait_for_debugger" {
+			s.WaitForDebugger = &b
+		} else {
+			s.ConsolePTY = &b
+		}
+	case "env":
+		pairs, _ := value.([]string)
+		s.Env = make(map[string]string, len(pairs))
+		for _, p := range pairs {
+			k, v, ok := strings.Cut(p, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid env value %q (expected KEY=VALUE)", p)
+			}
+			s.Env[k] = v
+		}
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+
+	r.apply(s, source)
+	return nil
+}
+
+// DeviceNames returns the devices to run on; empty means none was chosen.
+func (r *Resolved) DeviceNames() []string {
+	if len(r.Devices) > 0 {
+		return r.Devices
+	}
+	if r.Device != "" {
+		return []string{r.Device}
+	}
+	return nil
+}
+
+// LaunchOptions returns the resolved launch settings.
+func (r *Resolved) LaunchOptions() device.LaunchOptions {
+	return device.LaunchOptions{
+		Args:            r.Args,
+		Env:             r.EnvList(),
+		WaitForDebugger: r.WaitForDebugger != nil && *r.WaitForDebugger,
+		Stdout:          r.Stdout,
+		Stderr:          r.Stderr,
+		ConsolePTY:      r.ConsolePTY != nil && *r.ConsolePTY,
+	}
+}
+
+// EnvList returns Env as sorted KEY=VALUE pairs.
+func (r *Resolved) EnvList() []string {
+	list := make([]string, 0, len(r.Env))
+	for k, v := range r.Env {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return list
+}
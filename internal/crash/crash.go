@@ -0,0 +1,338 @@
+// Package crash finds and parses crash reports in Apple's JSON .ips format.
+//
+// An .ips file is a one-line JSON header (app name, bundle ID, bug type)
+// followed by a JSON body with the exception, threads and loaded images.
+package crash
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report is the part of a crash report needed to tell what happened.
+type Report struct {
+	Path      string    `json:"path"`
+	Process   string    `json:"process"`
+	BundleID  string    `json:"bundle_id,omitempty"`
+	PID       int       `json:"pid,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+
+	ExceptionType string   `json:"exception_type,omitempty"` // e.g. EXC_BAD_ACCESS
+	Signal        string   `json:"signal,omitempty"`         // e.g. SIGSEGV
+	Subtype       string   `json:"subtype,omitempty"`        // e.g. KERN_INVALID_ADDRESS at 0x0
+	Termination   string   `json:"termination,omitempty"`
+	Messages      []string `json:"messages,omitempty"` // application specific information, e.g. Swift fatal errors
+
+	FaultingThread int     `json:"faulting_thread"`
+	ThreadName     string  `json:"thread_name,omitempty"` // thread or queue name
+	Frames         []Frame `json:"frames"`                // faulting thread's backtrace
+	Images         []Image `json:"images"`                // binaries loaded in the process
+}
+
+// Image is a binary loaded in the crashed process.
+type Image struct {
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	UUID string `json:"uuid,omitempty"` // normalized, see NormalizeUUID
+	Arch string `json:"arch,omitempty"`
+	Base uint64 `json:"base"` // load address
+}
+
+// Frame is one entry of a backtrace.
+type Frame struct {
+	Image      string `json:"image"`
+	ImageIndex int    `json:"image_index"` // into Report.Images; -1 when unknown
+	Symbol     string `json:"symbol,omitempty"`
+	Offset     int64  `json:"offset"`  // from the symbol, or from the image when there is no symbol
+	Address    uint64 `json:"address"` // load address of the instruction
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line,omitempty"`
+}
+
+// String formats the frame like a crash report line, e.g.
+// "MyApp  ContentView.body.getter + 120 (ContentView.swift:42)".
+func (f Frame) String() string {
+	s := f.Image + "  "
+	switch {
+	case f.Symbol != "" && f.Offset != 0:
+		s += fmt.Sprintf("%s + %d", f.Symbol, f.Offset)
+	case f.Symbol != "":
+		s += f.Symbol
+	default:
+		s += fmt.Sprintf("0x%x", f.Address)
+	}
+	if f.File != "" {
+		s += fmt.Sprintf(" (%s:%d)", filepath.Base(f.File), f.Line)
+	}
+	return s
+}
+
+// Title is a one-line description such as
+// "EXC_BAD_ACCESS (SIGSEGV) KERN_INVALID_ADDRESS at 0x0".
+func (r *Report) Title() string {
+	parts := []string{}
+	if r.ExceptionType != "" {
+		parts = append(parts, r.ExceptionType)
+	}
+	if r.Signal != "" {
+		parts = append(parts, "("+r.Signal+")")
+	}
+	if r.Subtype != "" {
+		parts = append(parts, r.Subtype)
+	}
+	if len(parts) == 0 && r.Termination != "" {
+		parts = append(parts, r.Termination)
+	}
+	if len(parts) == 0 {
+		return "unknown exception"
+	}
+	return strings.Join(parts, " ")
+}
+
+type header struct {
+	AppName   string `json:"app_name"`
+	Name      string `json:"name"`
+	BundleID  string `json:"bundleID"`
+	BugType   string `json:"bug_type"`
+	Timestamp string `json:"timestamp"`
+}
+
+// bugTypeCrash is the .ips bug_type of an app crash, as opposed to hangs,
+// jetsam events and the like.
+const bugTypeCrash = "309"
+
+type body struct {
+	ProcName    string `json:"procName"`
+	PID         int    `json:"pid"`
+	CaptureTime string `json:"captureTime"`
+	BundleInfo  struct {
+		CFBundleIdentifier string `json:"CFBundleIdentifier"`
+	} `json:"bundleInfo"`
+	Exception struct {
+		Type    string `json:"type"`
+		Signal  string `json:"signal"`
+		Subtype string `json:"subtype"`
+	} `json:"exception"`
+	Termination struct {
+		Indicator string `json:"indicator"`
+	} `json:"termination"`
+	ASI            map[string][]string `json:"asi"`
+	FaultingThread int                 `json:"faultingThread"`
+	Threads        []struct {
+		Name      string `json:"name"`
+		Queue     string `json:"queue"`
+		Triggered bool   `json:"triggered"`
+		Frames    []struct {
+			ImageOffset    uint64 `json:"imageOffset"`
+			ImageIndex     int    `json:"imageIndex"`
+			Symbol         string `json:"symbol"`
+			SymbolLocation int64  `json:"symbolLocation"`
+			SourceFile     string `json:"sourceFile"`
+			SourceLine     int    `json:"sourceLine"`
+		} `json:"frames"`
+	} `json:"threads"`
+	UsedImages []struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+		UUID string `json:"uuid"`
+		Arch string `json:"arch"`
+		Base uint64 `json:"base"`
+	} `json:"usedImages"`
+}
+
+// ipsTime is the timestamp layout used in .ips headers and bodies; the
+// fractional seconds (two or four digits) are accepted when parsing.
+const ipsTime = "2006-01-02 15:04:05 -0700"
+
+// Parse decodes an .ips crash report.
+func Parse(data []byte) (*Report, error) {
+	first, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, errors.New("not an .ips crash report: missing body")
+	}
+
+	var h header
+	if err := json.Unmarshal(first, &h); err != nil {
+		return nil, fmt.Errorf("parse .ips header: %w", err)
+	}
+
+	var b body
+	if err := json.Unmarshal(rest, &b); err != nil {
+		return nil, fmt.Errorf("parse .ips body: %w", err)
+	}
+
+	r := &Report{
+		Process:        firstNonEmpty(b.ProcName, h.AppName, h.Name),
+		BundleID:       firstNonEmpty(b.BundleInfo.CFBundleIdentifier, h.BundleID),
+		PID:            b.PID,
+		ExceptionType:  b.Exception.Type,
+		Signal:         b.Exception.Signal,
+		Subtype:        b.Exception.Subtype,
+		Termination:    b.Termination.Indicator,
+		FaultingThread: b.FaultingThread,
+	}
+
+	for _, s := range []string{b.CaptureTime, h.Timestamp} {
+		if t, err := time.Parse(ipsTime, s); err == nil {
+			r.Timestamp = t
+			break
+		}
+	}
+
+	// Application specific information, keyed by image; sorted for stable output.
+	images := make([]string, 0, len(b.ASI))
+	for image := range b.ASI {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	for _, image := range images {
+		r.Messages = append(r.Messages, b.ASI[image]...)
+	}
+
+	for _, img := range b.UsedImages {
+		r.Images = append(r.Images, Image{
+			Name: firstNonEmpty(img.Name, filepath.Base(img.Path)),
+			Path: img.Path,
+			UUID: NormalizeUUID(img.UUID),
+			Arch: img.Arch,
+			Base: img.Base,
+		})
+	}
+
+	thread := -1
+	for i, t := range b.Threads {
+		if t.Triggered {
+			thread = i
+			break
+		}
+	}
+	if thread < 0 && b.FaultingThread >= 0 && b.FaultingThread < len(b.Threads) {
+		thread = b.FaultingThread
+	}
+	if thread >= 0 {
+		r.FaultingThread = thread
+		t := b.Threads[thread]
+		r.ThreadName = firstNonEmpty(t.Name, t.Queue)
+		for _, f := range t.Frames {
+			frame := Frame{ImageIndex: -1, Symbol: f.Symbol, File: f.SourceFile, Line: f.SourceLine}
+			if f.ImageIndex >= 0 && f.ImageIndex < len(r.Images) {
+				img := r.Images[f.ImageIndex]
+				frame.Image = img.Name
+				frame.ImageIndex = f.ImageIndex
+				frame.Address = img.Base + f.ImageOffset
+			}
+			if frame.Image == "" {
+				frame.Image = "???"
+			}
+			if f.Symbol != "" {
+				frame.Offset = f.SymbolLocation
+			} else {
+				frame.Offset = int64(f.ImageOffset)
+			}
+			r.Frames = append(r.Frames, frame)
+		}
+	}
+
+	return r, nil
+}
+
+// ReadFile parses the .ips report at path.
+func ReadFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.Path = path
+	return r, nil
+}
+
+// Dirs returns where crash reports for a simulator can land: the host's
+// DiagnosticReports (simulator apps are host processes) and the
+// simulator's own.
+func Dirs(udid string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	dirs := []string{filepath.Join(home, "Library", "Logs", "DiagnosticReports")}
+	if udid != "" {
+		dirs = append(dirs, filepath.Join(home, "Library", "Developer", "CoreSimulator", "Devices", udid, "data", "Library", "Logs", "DiagnosticReports"))
+	}
+	return dirs
+}
+
+// Find returns the newest crash report in dirs written after since for the
+// app with bundleID or process name, or nil when there is none.
+func Find(dirs []string, bundleID, process string, since time.Time) (*Report, error) {
+	type candidate struct {
+		path string
+		mod  time.Time
+	}
+	var candidates []candidate
+
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.ips"))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			info, err := os.Stat(p)
+			if err != nil || info.ModTime().Before(since) {
+				continue
+			}
+			candidates = append(candidates, candidate{p, info.ModTime()})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].mod.After(candidates[j].mod) })
+
+	for _, c := range candidates {
+		h, err := readHeader(c.path)
+		if err != nil || h.BugType != bugTypeCrash {
+			continue
+		}
+		if (bundleID != "" && h.BundleID == bundleID) || (process != "" && (h.AppName == process || h.Name == process)) {
+			return ReadFile(c.path)
+		}
+	}
+	return nil, nil
+}
+
+// readHeader reads just the first line of an .ips file.
+func readHeader(path string) (*header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	n, _ := f.Read(buf)
+	line, _, _ := bytes.Cut(buf[:n], []byte("\n"))
+
+	var h header
+	if err := json.Unmarshal(line, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
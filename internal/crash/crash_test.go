@@ -0,0 +1,80 @@
+package crash
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	rep, err := ReadFile("testdata/MyApp-2024-05-01-101500.ips")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rep.Process != "MyApp" || rep.BundleID != "com.example.MyApp" || rep.PID != 48213 {
+		t.Errorf("process = %q %q %d", rep.Process, rep.BundleID, rep.PID)
+	}
+	if want := time.Date(2024, 5, 1, 17, 14, 59, 812300000, time.UTC); !rep.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v, want %v", rep.Timestamp, want)
+	}
+	if got, want := rep.Title(), "EXC_BREAKPOINT (SIGTRAP)"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+	if want := []string{"MyApp/ContentView.swift:42: Fatal error: Unexpectedly found nil while unwrapping an Optional value"}; !reflect.DeepEqual(rep.Messages, want) {
+		t.Errorf("messages = %q", rep.Messages)
+	}
+	if rep.FaultingThread != 0 || rep.ThreadName != "com.apple.main-thread" {
+		t.Errorf("thread = %d %q", rep.FaultingThread, rep.ThreadName)
+	}
+
+	if len(rep.Images) != 4 {
+		t.Fatalf("got %d images, want 4", len(rep.Images))
+	}
+	app := Image{
+		Name: "MyApp",
+		Path: "/Users/dev/Library/Developer/CoreSimulator/Devices/5E3C1D2B-8A9F-4C7E-B6D5-4F3E2D1C0B9A/data/Containers/Bundle/Application/9F8E7D6C-5B4A-4938-2716-0F1E2D3C4B5A/MyApp.app/MyApp",
+		UUID: "6f1c2a3b4d5e3f608a7b9c0d1e2f3a4b",
+		Arch: "arm64",
+		Base: 0x100004000,
+	}
+	if rep.Images[0] != app {
+		t.Errorf("image 0 = %+v", rep.Images[0])
+	}
+	// Unnamed images take the file name of their path.
+	if rep.Images[2].Name != "Kit" {
+		t.Errorf("image 2 name = %q, want Kit", rep.Images[2].Name)
+	}
+
+	want := []string{
+		"libswiftCore.dylib  _assertionFailure(_:_:file:line:flags:) + 368",
+		"MyApp  0x100006f58",
+		"MyApp  MyAppApp.body.getter (MyAppApp.swift:17)",
+		"Kit  0x100011000",
+		"???  0x0",
+	}
+	if len(rep.Frames) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(rep.Frames), len(want))
+	}
+	for i, w := range want {
+		if got := rep.Frames[i].String(); got != w {
+			t.Errorf("frame %d = %q, want %q", i, got, w)
+		}
+	}
+	if f := rep.Frames[4]; f.ImageIndex != -1 || f.Offset != 512 {
+		t.Errorf("frame with unknown image = %+v", f)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		`{"app_name":"MyApp"}`,
+		"not json\n{}",
+		`{"app_name":"MyApp"}` + "\n{",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%q) succeeded", data)
+		}
+	}
+}
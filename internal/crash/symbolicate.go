@@ -0,0 +1,241 @@
+package crash
+
+import (
+	"context"
+	"debug/macho"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+)
+
+// lcUUID is the LC_UUID load command, which debug/macho leaves unparsed.
+const lcUUID = 0x1b
+
+// Binary is a Mach-O file (or one slice of a universal file) that can
+// resolve addresses for an image with the same UUID.
+type Binary struct {
+	Path string
+	Arch string
+}
+
+// NormalizeUUID lowercases a UUID and strips its dashes so UUIDs from .ips
+// reports and Mach-O load commands compare equal.
+func NormalizeUUID(uuid string) string {
+	return strings.ToLower(strings.ReplaceAll(uuid, "-", ""))
+}
+
+// UUIDs reads the LC_UUID of each architecture in the Mach-O file at path,
+// keyed by normalized UUID with the architecture name as value.
+func UUIDs(path string) (map[string]string, error) {
+	var files []*macho.File
+
+	fat, err := macho.OpenFat(path)
+	switch {
+	case err == nil:
+		defer fat.Close()
+		for _, arch := range fat.Arches {
+			files = append(files, arch.File)
+		}
+	case errors.Is(err, macho.ErrNotFat):
+		f, err := macho.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		files = append(files, f)
+	default:
+		return nil, err
+	}
+
+	uuids := make(map[string]string)
+	for _, f := range files {
+		for _, load := range f.Loads {
+			raw := load.Raw()
+			if len(raw) < 24 || f.ByteOrder.Uint32(raw) != lcUUID {
+				continue
+			}
+			uuids[fmt.Sprintf("%x", raw[8:24])] = archName(f.Cpu)
+		}
+	}
+	return uuids, nil
+}
+
+func archName(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.CpuAmd64:
+		return "x86_64"
+	case macho.CpuArm:
+		return "armv7"
+	case macho.Cpu386:
+		return "i386"
+	}
+	return cpu.String()
+}
+
+// Binaries lists the files in dirs that may hold symbols: DWARF files
+// inside dSYM bundles, app and framework executables, and plain
+// executables (command-line tools, SPM products).
+func Binaries(dirs ...string) []string {
+	patterns := []string{
+		"*.dSYM/Contents/Resources/DWARF/*",
+		"*.app/*",
+		"*.app/Contents/MacOS/*",
+		"*.app/Frameworks/*.framework/*",
+		"*.app/Contents/Frameworks/*.framework/Versions/A/*",
+		"*.framework/*",
+		"*",
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, dir := range dirs {
+		// A dSYM bundle or binary can be passed directly.
+		if strings.HasSuffix(dir, ".dSYM") {
+			dir = filepath.Join(dir, "Contents", "Resources", "DWARF")
+		}
+		if isMachO(dir) {
+			paths = append(paths, dir)
+			continue
+		}
+		for _, pattern := range patterns {
+			matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+			for _, m := range matches {
+				if !seen[m] && isMachO(m) {
+					seen[m] = true
+					paths = append(paths, m)
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// isMachO reports whether path is a regular file starting with a Mach-O or
+// universal binary magic number.
+func isMachO(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var magic [4]byte
+	if _, err := f.Read(magic[:]); err != nil {
+		return false
+	}
+	switch binary.BigEndian.Uint32(magic[:]) {
+	case macho.Magic32, macho.Magic64, 0xcefaedfe, 0xcffaedfe, macho.MagicFat:
+		return true
+	}
+	return false
+}
+
+// Index maps the UUIDs of binaries to the file and architecture to hand to
+// atos. dSYMs come first in Binaries' order, so they win over executables
+// with the same UUID.
+func Index(paths []string) map[string]Binary {
+	index := make(map[string]Binary)
+	for _, p := range paths {
+		uuids, err := UUIDs(p)
+		if err != nil {
+			continue
+		}
+		for uuid, arch := range uuids {
+			if _, ok := index[uuid]; !ok {
+				index[uuid] = Binary{Path: p, Arch: arch}
+			}
+		}
+	}
+	return index
+}
+
+// Symbolicator resolves crash report frames to symbols and file:line with
+// atos.
+type Symbolicator struct {
+	runner *process.Runner
+}
+
+func NewSymbolicator(opts ...process.Option) *Symbolicator {
+	return &Symbolicator{runner: process.NewRunner(opts...)}
+}
+
+// Symbolicate fills in the symbol, file and line of rep's frames whose
+// image has a binary in index, running atos once per image. It returns
+// how many frames were resolved.
+func (s *Symbolicator) Symbolicate(ctx context.Context, rep *Report, index map[string]Binary) (int, error) {
+	// Frames that still need a file:line, grouped by image.
+	byImage := make(map[int][]int)
+	for i, f := range rep.Frames {
+		if f.File != "" || f.ImageIndex < 0 {
+			continue
+		}
+		if _, ok := index[rep.Images[f.ImageIndex].UUID]; ok {
+			byImage[f.ImageIndex] = append(byImage[f.ImageIndex], i)
+		}
+	}
+
+	resolved := 0
+	for imageIndex, frames := range byImage {
+		img := rep.Images[imageIndex]
+		bin := index[img.UUID]
+		arch := firstNonEmpty(img.Arch, bin.Arch)
+
+		args := []string{"atos", "-o", bin.Path, "-arch", arch, "-l", fmt.Sprintf("0x%x", img.Base)}
+		for _, i := range frames {
+			args = append(args, fmt.Sprintf("0x%x", rep.Frames[i].Address))
+		}
+
+		output, err := s.runner.RunSilent(ctx, "xcrun", args)
+		if err != nil {
+			return resolved, fmt.Errorf("atos %s: %w", img.Name, err)
+		}
+
+		lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+		for n, i := range frames {
+			if n >= len(lines) {
+				break
+			}
+			if ParseAtos(lines[n], &rep.Frames[i]) {
+				resolved++
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// atosLine matches atos output such as
+// "ContentView.body.getter (in MyApp) (ContentView.swift:42)" or
+// "specialized foo() (in MyApp) + 40".
+var atosLine = regexp.MustCompile(`^(.+?) \(in ([^)]+)\)(?: \((.+):(\d+)\)| \+ (\d+))?$`)
+
+// ParseAtos updates f from one line of atos output, reporting whether the
+// address was resolved (atos echoes addresses it can't resolve).
+func ParseAtos(line string, f *Frame) bool {
+	m := atosLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return false
+	}
+
+	f.Symbol = m[1]
+	if m[3] != "" {
+		f.File = m[3]
+		f.Line, _ = strconv.Atoi(m[4])
+		f.Offset = 0
+	} else if m[5] != "" {
+		f.Offset, _ = strconv.ParseInt(m[5], 10, 64)
+	}
+	return true
+}
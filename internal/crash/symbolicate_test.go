@@ -0,0 +1,157 @@
+package crash
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+)
+
+func TestParseAtos(t *testing.T) {
+	tests := []struct {
+		line string
+		ok   bool
+		want Frame
+	}{
+		{
+			line: "ContentView.body.getter (in MyApp) (ContentView.swift:42)",
+			ok:   true,
+			want: Frame{Symbol: "ContentView.body.getter", File: "ContentView.swift", Line: 42},
+		},
+		{
+			line: "closure #1 in closure #2 in MyAppApp.body.getter (in MyApp) (MyAppApp.swift:17)",
+			ok:   true,
+			want: Frame{Symbol: "closure #1 in closure #2 in MyAppApp.body.getter", File: "MyAppApp.swift", Line: 17},
+		},
+		{
+			line: "specialized Array.subscript.getter (in MyApp) + 40",
+			ok:   true,
+			want: Frame{Symbol: "specialized Array.subscript.getter", Offset: 40},
+		},
+		{
+			line: "  main (in MyApp) (<compiler-generated>:0)\n",
+			ok:   true,
+			want: Frame{Symbol: "main", File: "<compiler-generated>", Line: 0},
+		},
+		{line: "0x100006f58", ok: false, want: Frame{Offset: 12120}},
+		{line: "", ok: false, want: Frame{Offset: 12120}},
+	}
+
+	for _, tt := range tests {
+		f := Frame{Offset: 12120}
+		if ok := ParseAtos(tt.line, &f); ok != tt.ok {
+			t.Errorf("ParseAtos(%q) = %v, want %v", tt.line, ok, tt.ok)
+		}
+		if f != tt.want {
+			t.Errorf("ParseAtos(%q) frame = %+v, want %+v", tt.line, f, tt.want)
+		}
+	}
+}
+
+// writeMachO writes a minimal 64-bit Mach-O with a single LC_UUID.
+func writeMachO(t *testing.T, path string, cpu uint32, uuid string) {
+	t.Helper()
+
+	raw, err := hex.DecodeString(uuid)
+	if err != nil || len(raw) != 16 {
+		t.Fatalf("bad uuid %q", uuid)
+	}
+
+	le := binary.LittleEndian
+	var b []byte
+	// mach_header_64: magic, cputype, cpusubtype, filetype (MH_EXECUTE),
+	// ncmds, sizeofcmds, flags, reserved.
+	for _, v := range []uint32{0xfeedfacf, cpu, 0, 2, 1, 24, 0, 0} {
+		b = le.AppendUint32(b, v)
+	}
+	b = le.AppendUint32(b, lcUUID)
+	b = le.AppendUint32(b, 24)
+	b = append(b, raw...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const (
+	cpuArm64 = 0x0100000c
+	appUUID  = "6f1c2a3b4d5e3f608a7b9c0d1e2f3a4b"
+	kitUUID  = "0b1c2d3e4f5036178293a4b5c6d7e8f9"
+)
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	dwarf := filepath.Join(dir, "MyApp.app.dSYM", "Contents", "Resources", "DWARF", "MyApp")
+	app := filepath.Join(dir, "MyApp.app", "MyApp")
+	kit := filepath.Join(dir, "MyApp.app", "Frameworks", "Kit.framework", "Kit")
+	writeMachO(t, dwarf, cpuArm64, appUUID)
+	writeMachO(t, app, cpuArm64, appUUID)
+	writeMachO(t, kit, cpuArm64, kitUUID)
+	if err := os.WriteFile(filepath.Join(dir, "MyApp.app", "Info.plist"), []byte("<plist/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	uuids, err := UUIDs(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{appUUID: "arm64"}; !reflect.DeepEqual(uuids, want) {
+		t.Errorf("UUIDs = %v, want %v", uuids, want)
+	}
+
+	// The dSYM wins over the executable with the same UUID; non-Mach-O
+	// files are ignored.
+	want := map[string]Binary{
+		appUUID: {Path: dwarf, Arch: "arm64"},
+		kitUUID: {Path: kit, Arch: "arm64"},
+	}
+	if got := Index(Binaries(dir)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Index = %v, want %v", got, want)
+	}
+}
+
+func TestSymbolicate(t *testing.T) {
+	rep, err := ReadFile("testdata/MyApp-2024-05-01-101500.ips")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only MyApp has symbols; its frame that already has a file is skipped.
+	index := map[string]Binary{appUUID: {Path: "/dsyms/MyApp", Arch: "arm64"}}
+	fake := process.NewFakeExecutor().
+		On("xcrun atos -o /dsyms/MyApp -arch arm64 -l 0x100004000 0x100006f58", process.FakeResponse{
+			Stdout: "ContentView.body.getter (in MyApp) (ContentView.swift:42)\n",
+		})
+
+	n, err := NewSymbolicator(process.WithExecutor(fake)).Symbolicate(context.Background(), rep, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("resolved %d frames, want 1", n)
+	}
+	if len(fake.Calls()) != 1 {
+		t.Errorf("atos calls = %v", fake.Calls())
+	}
+
+	want := []string{
+		"libswiftCore.dylib  _assertionFailure(_:_:file:line:flags:) + 368",
+		"MyApp  ContentView.body.getter (ContentView.swift:42)",
+		"MyApp  MyAppApp.body.getter (MyAppApp.swift:17)",
+		"Kit  0x100011000",
+		"???  0x0",
+	}
+	for i, w := range want {
+		if got := rep.Frames[i].String(); got != w {
+			t.Errorf("frame %d = %q, want %q", i, got, w)
+		}
+	}
+}
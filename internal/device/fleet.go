@@ -0,0 +1,157 @@
+package device
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fleet is a declarative list of simulators, read from a file such as
+//
+//	simulators:
+//	  - name: CI iPhone
+//	    device_type: iPhone 15 Pro
+//	    runtime: iOS 17.5
+//	  - name: CI iPad
+//	    device_type: iPad Air 11-inch (M2)
+//	    runtime: iOS 17.5
+//
+// Device types and runtimes take the same names or identifiers as
+// `swiftctl devices create`.
+type Fleet struct {
+	Simulators []Spec `yaml:"simulators"`
+}
+
+// Spec is one simulator in a Fleet.
+type Spec struct {
+	Name       string `yaml:"name"`
+	DeviceType string `yaml:"device_type"`
+	Runtime    string `yaml:"runtime"`
+
+	// CoreSimulator identifiers DeviceType and Runtime resolve to; set by
+	// the caller before PlanSync.
+	DeviceTypeID string `yaml:"-"`
+	RuntimeID    string `yaml:"-"`
+}
+
+// LoadFleet reads and validates a fleet file.
+func LoadFleet(path string) (*Fleet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f Fleet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, s := range f.Simulators {
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("%s: simulator %d has no name", path, i+1)
+		case s.DeviceType == "" || s.Runtime == "":
+			return nil, fmt.Errorf("%s: %s needs a device_type and a runtime", path, s.Name)
+		case seen[s.Name]:
+			return nil, fmt.Errorf("%s: %s is listed twice", path, s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return &f, nil
+}
+
+// Sync actions.
+const (
+	ActionKeep     = "keep"
+	ActionCreate   = "create"
+	ActionRecreate = "recreate" // exists with another device type or runtime, or is unavailable
+	ActionDelete   = "delete"   // a duplicate of a declared name, or unmanaged with prune
+)
+
+// Change is one step of a sync plan.
+type Change struct {
+	Action string
+	Name   string
+	Spec   *Spec   // nil for deletions
+	Device *Device // the existing simulator; nil for creations
+	Reason string
+}
+
+// PlanSync compares the declared specs with the existing simulators and
+// returns the changes that make them match, in spec order followed by
+// deletions. Simulators are matched by name. Unavailable simulators are
+// never kept, so existing should include them (see Manager.Simulators).
+// With prune, simulators that aren't declared are deleted; otherwise they
+// are left alone.
+func PlanSync(specs []Spec, existing []*Device, prune bool) []Change {
+	byName := make(map[string][]*Device)
+	for _, d := range existing {
+		if d.Type == DeviceTypeSimulator {
+			byName[d.Name] = append(byName[d.Name], d)
+		}
+	}
+
+	var changes, deletes []Change
+	declared := make(map[string]bool, len(specs))
+	for i := range specs {
+		spec := &specs[i]
+		declared[spec.Name] = true
+
+		devs := byName[spec.Name]
+		var kept *Device
+		for _, d := range devs {
+			if d.IsAvailable && d.DeviceTypeID == spec.DeviceTypeID && d.RuntimeID == spec.RuntimeID {
+				kept = d
+				break
+			}
+		}
+
+		switch {
+		case kept != nil:
+			changes = append(changes, Change{Action: ActionKeep, Name: spec.Name, Spec: spec, Device: kept})
+		case len(devs) > 0:
+			// The first mismatch is replaced; any others go below.
+			changes = append(changes, Change{Action: ActionRecreate, Name: spec.Name, Spec: spec, Device: devs[0], Reason: mismatch(devs[0], spec)})
+			kept = devs[0]
+		default:
+			changes = append(changes, Change{Action: ActionCreate, Name: spec.Name, Spec: spec})
+		}
+
+		for _, d := range devs {
+			if d != kept {
+				deletes = append(deletes, Change{Action: ActionDelete, Name: d.Name, Device: d, Reason: "duplicate"})
+			}
+		}
+	}
+
+	if prune {
+		for _, d := range existing {
+			if d.Type == DeviceTypeSimulator && !declared[d.Name] {
+				deletes = append(deletes, Change{Action: ActionDelete, Name: d.Name, Device: d, Reason: "not declared"})
+			}
+		}
+	}
+
+	return append(changes, deletes...)
+}
+
+// mismatch describes how d differs from spec.
+func mismatch(d *Device, spec *Spec) string {
+	switch {
+	case !d.IsAvailable:
+		return "unavailable"
+	case d.DeviceTypeID != spec.DeviceTypeID && d.RuntimeID != spec.RuntimeID:
+		return "device type and runtime differ"
+	case d.DeviceTypeID != spec.DeviceTypeID:
+		return "device type differs"
+	default:
+		return "runtime differs"
+	}
+}
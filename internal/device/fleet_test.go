@@ -0,0 +1,150 @@
+package device
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+)
+
+const (
+	iPhone15  = "com.apple.CoreSimulator.SimDeviceType.iPhone-15"
+	iPhone16  = "com.apple.CoreSimulator.SimDeviceType.iPhone-16"
+	iOS17     = "com.apple.CoreSimulator.SimRuntime.iOS-17-5"
+	iOS18     = "com.apple.CoreSimulator.SimRuntime.iOS-18-0"
+	unmanaged = "Scratch"
+)
+
+func sim(udid, name, deviceType, runtime string) *Device {
+	return &Device{UDID: udid, Name: name, Type: DeviceTypeSimulator, IsAvailable: true, DeviceTypeID: deviceType, RuntimeID: runtime}
+}
+
+func TestPlanSync(t *testing.T) {
+	specs := []Spec{
+		{Name: "Keep", DeviceTypeID: iPhone15, RuntimeID: iOS17},
+		{Name: "New", DeviceTypeID: iPhone15, RuntimeID: iOS17},
+		{Name: "Runtime", DeviceTypeID: iPhone15, RuntimeID: iOS18},
+		{Name: "Both", DeviceTypeID: iPhone16, RuntimeID: iOS18},
+		{Name: "Broken", DeviceTypeID: iPhone15, RuntimeID: iOS17},
+		{Name: "Dup", DeviceTypeID: iPhone15, RuntimeID: iOS17},
+	}
+
+	broken := sim("5", "Broken", iPhone15, iOS17)
+	broken.IsAvailable = false
+	staleDup := sim("7", "Dup", iPhone16, iOS17)
+	staleDup.IsAvailable = false
+	existing := []*Device{
+		sim("1", "Keep", iPhone15, iOS17),
+		sim("3", "Runtime", iPhone15, iOS17),
+		sim("4", "Both", iPhone15, iOS17),
+		broken,
+		sim("6", "Dup", iPhone16, iOS17),
+		staleDup,
+		sim("8", "Dup", iPhone15, iOS17),
+		sim("9", unmanaged, iPhone15, iOS17),
+		{UDID: "00008110-001A2B3C4D5E801E", Name: "Dev iPhone", Type: DeviceTypePhysical},
+	}
+
+	type step struct {
+		action, name, udid, reason string
+	}
+	synced := []step{
+		{ActionKeep, "Keep", "1", ""},
+		{ActionCreate, "New", "", ""},
+		{ActionRecreate, "Runtime", "3", "runtime differs"},
+		{ActionRecreate, "Both", "4", "device type and runtime differ"},
+		{ActionRecreate, "Broken", "5", "unavailable"},
+		{ActionKeep, "Dup", "8", ""},
+		{ActionDelete, "Dup", "6", "duplicate"},
+		{ActionDelete, "Dup", "7", "duplicate"},
+	}
+
+	tests := []struct {
+		name     string
+		specs    []Spec
+		existing []*Device
+		prune    bool
+		want     []step
+	}{
+		{
+			name:     "without prune",
+			specs:    specs,
+			existing: existing,
+			want:     synced,
+		},
+		{
+			name:     "prune",
+			specs:    specs,
+			existing: existing,
+			prune:    true,
+			want:     append(synced[:len(synced):len(synced)], step{ActionDelete, unmanaged, "9", "not declared"}),
+		},
+		{
+			name:     "nothing declared",
+			existing: existing[:2],
+			prune:    true,
+			want: []step{
+				{ActionDelete, "Keep", "1", "not declared"},
+				{ActionDelete, "Runtime", "3", "not declared"},
+			},
+		},
+		{
+			name:  "nothing exists",
+			specs: specs[:1],
+			want:  []step{{ActionCreate, "Keep", "", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []step
+			for _, c := range PlanSync(tt.specs, tt.existing, tt.prune) {
+				s := step{action: c.Action, name: c.Name, reason: c.Reason}
+				if c.Device != nil {
+					s.udid = c.Device.UDID
+				}
+				if (c.Spec == nil) != (c.Action == ActionDelete) {
+					t.Errorf("%s %s: spec = %v", c.Action, c.Name, c.Spec)
+				}
+				got = append(got, s)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimulatorsIncludesUnavailable(t *testing.T) {
+	fake := process.NewFakeExecutor().On("xcrun simctl list devices -j", process.FakeResponse{Stdout: `{
+		"devices": {
+			"com.apple.CoreSimulator.SimRuntime.iOS-17-5": [
+				{"udid": "A", "name": "CI iPhone", "state": "Shutdown", "isAvailable": true,
+				 "deviceTypeIdentifier": "com.apple.CoreSimulator.SimDeviceType.iPhone-15"}
+			],
+			"com.apple.CoreSimulator.SimRuntime.iOS-16-4": [
+				{"udid": "B", "name": "Old iPhone", "state": "Shutdown", "isAvailable": false,
+				 "availabilityError": "runtime profile not found",
+				 "deviceTypeIdentifier": "com.apple.CoreSimulator.SimDeviceType.iPhone-14"}
+			]
+		}
+	}`})
+	m := NewManager(process.WithExecutor(fake))
+
+	all, err := m.Simulators(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].UDID != "A" || !all[0].IsAvailable || all[1].UDID != "B" || all[1].IsAvailable {
+		t.Errorf("Simulators = %+v", all)
+	}
+
+	available, err := m.listSimulators(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(available) != 1 || available[0].UDID != "A" {
+		t.Errorf("listSimulators = %+v", available)
+	}
+}
@@ -0,0 +1,134 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+)
+
+// LaunchOptions controls how an app is started.
+type LaunchOptions struct {
+	Args            []string `json:"args,omitempty"`
+	Env             []string `json:"env,omitempty"` // KEY=VALUE pairs for the app's environment
+	WaitForDebugger bool     `json:"wait_for_debugger,omitempty"`
+
+	// Stdout and Stderr redirect the app's output to files on the host
+	// (simulators only).
+	Stdout string `json:"stdout,omitempty"`
+	Stderr string `json:"stderr,omitempty"`
+
+	// ConsolePTY attaches the app's stdio through a pseudo-terminal instead
+	// of following the unified log (simulators only).
+	ConsolePTY bool `json:"console_pty,omitempty"`
+}
+
+// Validate reports options that can't be used together or on dev.
+func (o LaunchOptions) Validate(dev *Device) error {
+	for _, kv := range o.Env {
+		if k, _, ok := strings.Cut(kv, "="); !ok || k == "" {
+			return fmt.Errorf("invalid environment variable %q (expected KEY=VALUE)", kv)
+		}
+	}
+
+	if o.ConsolePTY && (o.Stdout != "" || o.Stderr != "") {
+		return fmt.Errorf("--console-pty can't be combined with --stdout/--stderr")
+	}
+
+	if dev != nil && dev.Type == DeviceTypePhysical {
+		if o.Stdout != "" || o.Stderr != "" {
+			return fmt.Errorf("--stdout/--stderr are only supported on simulators (device console output is streamed instead)")
+		}
+		if o.ConsolePTY {
+			return fmt.Errorf("--console-pty is only supported on simulators (device console output is always attached)")
+		}
+	}
+
+	return nil
+}
+
+// Attached reports whether launching keeps the app's output attached, so
+// the launch command streams until the app exits.
+func (o LaunchOptions) Attached(dev *Device) bool {
+	return o.ConsolePTY || dev.Type == DeviceTypePhysical
+}
+
+// simctlLaunchArgs builds `xcrun simctl launch` arguments.
+func simctlLaunchArgs(dev *Device, bundleID string, opts LaunchOptions) ([]string, error) {
+	args := []string{"simctl", "launch"}
+	if opts.WaitForDebugger {
+		args = append(args, "--wait-for-debugger")
+	}
+	if opts.ConsolePTY {
+		args = append(args, "--console-pty", "--terminate-running-process")
+	}
+	for _, redirect := range [][2]string{{"--stdout", opts.Stdout}, {"--stderr", opts.Stderr}} {
+		if redirect[1] == "" {
+			continue
+		}
+		abs, err := filepath.Abs(redirect[1])
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, redirect[0]+"="+abs)
+	}
+	args = append(args, dev.UDID, bundleID)
+	return append(args, opts.Args...), nil
+}
+
+// devicectlLaunchArgs builds `devicectl device process launch` arguments.
+func devicectlLaunchArgs(dev *Device, bundleID string, opts LaunchOptions, console bool) []string {
+	args := []string{"device", "process", "launch", "--device", dev.UDID, "--terminate-existing"}
+	if console {
+		args = append(args, "--console")
+	}
+	if opts.WaitForDebugger {
+		args = append(args, "--start-stopped")
+	}
+	if len(opts.Env) > 0 {
+		vars := make(map[string]string, len(opts.Env))
+		for _, kv := range opts.Env {
+			k, v, _ := strings.Cut(kv, "=")
+			vars[k] = v
+		}
+		data, _ := json.Marshal(vars)
+		args = append(args, "--environment-variables", string(data))
+	}
+	args = append(args, bundleID)
+	return append(args, opts.Args...)
+}
+
+// simctlChildEnv prefixes variables with SIMCTL_CHILD_, which simctl strips
+// before passing them to the launched app.
+func simctlChildEnv(env []string) []string {
+	out := make([]string, len(env))
+	for i, kv := range env {
+		out[i] = "SIMCTL_CHILD_" + kv
+	}
+	return out
+}
+
+// StreamConsole launches the app with its stdout and stderr attached,
+// replacing any running instance, and streams the output until the app
+// exits. Physical devices always launch this way since devicectl has no
+// equivalent of `log stream`; simulators do with ConsolePTY.
+func (m *Manager) StreamConsole(ctx context.Context, device *Device, bundleID string, opts LaunchOptions) (<-chan process.OutputLine, <-chan error) {
+	if device.Type == DeviceTypePhysical {
+		return m.runner.Run(ctx, "xcrun", append([]string{"devicectl"}, devicectlLaunchArgs(device, bundleID, opts, true)...))
+	}
+
+	opts.ConsolePTY = true
+	args, err := simctlLaunchArgs(device, bundleID, opts)
+	if err != nil {
+		outChan := make(chan process.OutputLine)
+		errChan := make(chan error, 1)
+		close(outChan)
+		errChan <- err
+		close(errChan)
+		return outChan, errChan
+	}
+	return m.runner.StreamCommand(ctx, process.Command{Name: "xcrun", Args: args, Env: simctlChildEnv(opts.Env)})
+}
@@ -0,0 +1,47 @@
+package device
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/arnavsurve/swiftctl/internal/process"
+)
+
+func TestDevicectlLaunchArgs(t *testing.T) {
+	dev := &Device{UDID: "00008110-001A2B3C4D5E801E", Type: DeviceTypePhysical}
+	opts := LaunchOptions{
+		Args:            []string{"-AppleLanguages", "(fr)"},
+		Env:             []string{"API_URL=http://localhost:8080"},
+		WaitForDebugger: true,
+	}
+
+	// The JSON output flags come before the subcommand; everything after
+	// the bundle ID belongs to the app.
+	want := []string{
+		"devicectl", "--quiet", "--json-output", "/dev/stdout",
+		"device", "process", "launch", "--device", "00008110-001A2B3C4D5E801E", "--terminate-existing",
+		"--start-stopped",
+		"--environment-variables", `{"API_URL":"http://localhost:8080"}`,
+		"com.example.MyApp", "-AppleLanguages", "(fr)",
+	}
+	if got := devicectlArgs(devicectlLaunchArgs(dev, "com.example.MyApp", opts, false)...); !reflect.DeepEqual(got, want) {
+		t.Errorf("args =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLaunchPhysicalPID(t *testing.T) {
+	dev := &Device{UDID: "00008110-001A2B3C4D5E801E", Name: "Dev iPhone", Type: DeviceTypePhysical}
+	fake := process.NewFakeExecutor().On(
+		"xcrun devicectl --quiet --json-output /dev/stdout device process launch --device 00008110-001A2B3C4D5E801E --terminate-existing com.example.MyApp --verbose",
+		process.FakeResponse{Stdout: `{"result":{"process":{"processIdentifier":4242}}}`},
+	)
+
+	pid, err := NewManager(process.WithExecutor(fake)).Launch(context.Background(), dev, "com.example.MyApp", LaunchOptions{Args: []string{"--verbose"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != 4242 {
+		t.Errorf("pid = %d, want 4242", pid)
+	}
+}
@@ -2,8 +2,11 @@ package device
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/arnavsurve/swiftctl/internal/process"
 	"github.com/tidwall/gjson"
@@ -13,13 +16,65 @@ type Manager struct {
 	runner *process.Runner
 }
 
-func NewManager() *Manager {
+func NewManager(opts ...process.Option) *Manager {
 	return &Manager{
-		runner: process.NewRunner(),
+		runner: process.NewRunner(opts...),
 	}
 }
 
 func (m *Manager) List(ctx context.Context, platform Platform, onlyBooted bool) ([]*Device, error) {
+	simulators, err := m.listSimulators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []*Device
+	for _, d := range simulators {
+		if platform != "" && d.Platform != platform {
+			continue
+		}
+		if onlyBooted && d.State != StateBooted {
+			continue
+		}
+		devices = append(devices, d)
+	}
+
+	// devicectl ships with Xcode 15+, so physical devices are best-effort.
+	if physical, err := m.listPhysical(ctx); err == nil {
+		for _, d := range physical {
+			if platform != "" && d.Platform != platform {
+				continue
+			}
+			if onlyBooted && d.State != StateConnected {
+				continue
+			}
+			devices = append(devices, d)
+		}
+	}
+
+	return devices, nil
+}
+
+// listSimulators returns every available simulator.
+func (m *Manager) listSimulators(ctx context.Context) ([]*Device, error) {
+	all, err := m.Simulators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []*Device
+	for _, d := range all {
+		if d.IsAvailable {
+			devices = append(devices, d)
+		}
+	}
+	return devices, nil
+}
+
+// Simulators returns every simulator, including unavailable ones whose
+// runtime or device type is no longer installed. List skips those, but
+// they still hold their name and disk space.
+func (m *Manager) Simulators(ctx context.Context) ([]*Device, error) {
 	output, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "list", "devices", "-j"})
 	if err != nil {
 		return nil, fmt.Errorf("simctl list: %w", err)
@@ -29,28 +84,19 @@ func (m *Manager) List(ctx context.Context, platform Platform, onlyBooted bool)
 
 	gjson.ParseBytes(output).Get("devices").ForEach(func(runtime, devicesArray gjson.Result) bool {
 		plat, version := parseRuntime(runtime.String())
-		if platform != "" && plat != platform {
-			return true
-		}
 
 		devicesArray.ForEach(func(_, dev gjson.Result) bool {
-			if !dev.Get("isAvailable").Bool() {
-				return true
-			}
-
-			state := DeviceState(dev.Get("state").String())
-			if onlyBooted && state != StateBooted {
-				return true
-			}
-
 			devices = append(devices, &Device{
 				UDID:        dev.Get("udid").String(),
 				Name:        dev.Get("name").String(),
 				Type:        DeviceTypeSimulator,
 				Platform:    plat,
 				OSVersion:   version,
-				State:       state,
-				IsAvailable: true,
+				State:       DeviceState(dev.Get("state").String()),
+				IsAvailable: dev.Get("isAvailable").Bool(),
+
+				DeviceTypeID: dev.Get("deviceTypeIdentifier").String(),
+				RuntimeID:    runtime.String(),
 			})
 			return true
 		})
@@ -89,22 +135,107 @@ func (m *Manager) Get(ctx context.Context, nameOrUDID string) (*Device, error) {
 	return nil, fmt.Errorf("device not found: %s", nameOrUDID)
 }
 
-func (m *Manager) Boot(ctx context.Context, device *Device) error {
-	if device.State == StateBooted {
+// DefaultBootTimeout is how long Boot waits for a simulator to finish
+// booting when BootOptions.Timeout is zero.
+const DefaultBootTimeout = 3 * time.Minute
+
+// BootOptions control how a simulator is booted.
+type BootOptions struct {
+	Wait     bool          // wait until the simulator is ready to install and launch apps
+	Timeout  time.Duration // for Wait; 0 means DefaultBootTimeout
+	Headless bool          // don't open Simulator.app, e.g. on CI
+
+	// Progress is called with each boot stage reported while waiting,
+	// e.g. "Data Migration".
+	Progress func(stage string)
+}
+
+// Boot boots a simulator; for physical devices it only checks they are
+// connected. With opts.Wait it uses `simctl bootstatus -b`, which boots the
+// simulator if needed and returns once SpringBoard and the services apps
+// need are up. A plain `simctl boot` returns as soon as booting starts, so
+// an install right after it can fail on a cold boot.
+func (m *Manager) Boot(ctx context.Context, device *Device, opts BootOptions) error {
+	if device.Type == DeviceTypePhysical {
+		if device.State != StateConnected {
+			return fmt.Errorf("%s is not connected", device.Name)
+		}
+		return nil
+	}
+
+	if device.State == StateBooted && !opts.Wait {
 		return nil
 	}
 
-	_, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "boot", device.UDID})
-	if err != nil {
-		return fmt.Errorf("boot %s: %w", device.Name, err)
+	if opts.Wait {
+		if err := m.waitForBoot(ctx, device, opts); err != nil {
+			return err
+		}
+	} else {
+		_, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "boot", device.UDID})
+		if err != nil {
+			return fmt.Errorf("boot %s: %w", device.Name, err)
+		}
 	}
 
-	_, _ = m.runner.RunSilent(ctx, "open", []string{"-a", "Simulator"})
+	if !opts.Headless {
+		_, _ = m.runner.RunSilent(ctx, "open", []string{"-a", "Simulator"})
+	}
 
 	return nil
 }
 
+// waitForBoot runs `simctl bootstatus -b` until the simulator has booted or
+// the timeout passes, reporting the stages it prints.
+func (m *Manager) waitForBoot(ctx context.Context, device *Device, opts BootOptions) error {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultBootTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lines, errs := m.runner.Run(ctx, "xcrun", []string{"simctl", "bootstatus", device.UDID, "-b"})
+
+	stage := ""
+	for line := range lines {
+		if s, ok := parseBootStage(line.Content); ok && s != stage {
+			stage = s
+			if opts.Progress != nil {
+				opts.Progress(stage)
+			}
+		}
+	}
+
+	err := <-errs
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		if stage != "" {
+			return fmt.Errorf("%s did not finish booting within %s (waiting on %s)", device.Name, timeout, stage)
+		}
+		return fmt.Errorf("%s did not finish booting within %s", device.Name, timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("boot %s: %w", device.Name, err)
+	}
+	return nil
+}
+
+// parseBootStage extracts the stage from a `simctl bootstatus` line such as
+// "Waiting on Data Migration" or "Waiting on System App...".
+func parseBootStage(line string) (string, bool) {
+	stage, ok := strings.CutPrefix(strings.TrimSpace(line), "Waiting on ")
+	if !ok {
+		return "", false
+	}
+	stage = strings.TrimRight(stage, ". ")
+	return stage, stage != ""
+}
+
 func (m *Manager) Shutdown(ctx context.Context, device *Device) error {
+	if device.Type == DeviceTypePhysical {
+		return fmt.Errorf("cannot shut down physical device %s", device.Name)
+	}
+
 	if device.State == StateShutdown {
 		return nil
 	}
@@ -123,6 +254,10 @@ func (m *Manager) ShutdownAll(ctx context.Context) error {
 }
 
 func (m *Manager) Install(ctx context.Context, device *Device, appPath string) error {
+	if device.Type == DeviceTypePhysical {
+		return m.installPhysical(ctx, device, appPath)
+	}
+
 	_, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "install", device.UDID, appPath})
 	if err != nil {
 		return fmt.Errorf("install on %s: %w", device.Name, err)
@@ -130,12 +265,23 @@ func (m *Manager) Install(ctx context.Context, device *Device, appPath string) e
 	return nil
 }
 
-// Launch starts an app and returns its PID (0 if unknown).
-func (m *Manager) Launch(ctx context.Context, device *Device, bundleID string, args []string) (int, error) {
-	cmdArgs := []string{"simctl", "launch", device.UDID, bundleID}
-	cmdArgs = append(cmdArgs, args...)
+// Launch starts an app and returns its PID (0 if unknown). Use
+// StreamConsole instead when opts.Attached(device) is true.
+func (m *Manager) Launch(ctx context.Context, device *Device, bundleID string, opts LaunchOptions) (int, error) {
+	if err := opts.Validate(device); err != nil {
+		return 0, err
+	}
+
+	if device.Type == DeviceTypePhysical {
+		return m.launchPhysical(ctx, device, bundleID, opts)
+	}
+
+	cmdArgs, err := simctlLaunchArgs(device, bundleID, opts)
+	if err != nil {
+		return 0, err
+	}
 
-	output, err := m.runner.RunSilent(ctx, "xcrun", cmdArgs)
+	output, err := m.runner.RunCommand(ctx, process.Command{Name: "xcrun", Args: cmdArgs, Env: simctlChildEnv(opts.Env)})
 	if err != nil {
 		return 0, fmt.Errorf("launch %s: %w", bundleID, err)
 	}
@@ -150,7 +296,74 @@ func (m *Manager) Launch(ctx context.Context, device *Device, bundleID string, a
 	return 0, nil
 }
 
+// Running reports whether the app process pid is still alive. Simulator
+// apps are host processes, so this asks ps; physical devices aren't
+// supported.
+func (m *Manager) Running(ctx context.Context, device *Device, pid int) (bool, error) {
+	if device.Type == DeviceTypePhysical {
+		return false, fmt.Errorf("process monitoring is only supported on simulators")
+	}
+
+	output, err := m.runner.RunSilent(ctx, "ps", []string{"-p", strconv.Itoa(pid), "-o", "pid="})
+	if err != nil {
+		// ps exits 1 when no process matched.
+		if process.ExitCode(err) == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// AppPID returns the PID of the running app with bundleID, or an error if
+// it isn't running. Simulators are asked through launchctl, which lists
+// apps as UIKitApplication:<bundle ID>[...].
+func (m *Manager) AppPID(ctx context.Context, device *Device, bundleID string) (int, error) {
+	if device.Type == DeviceTypePhysical {
+		pids, err := m.physicalAppPIDs(ctx, device, bundleID)
+		if err != nil {
+			return 0, err
+		}
+		if len(pids) == 0 {
+			return 0, fmt.Errorf("%s is not running on %s", bundleID, device.Name)
+		}
+		return pids[0], nil
+	}
+
+	output, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "spawn", device.UDID, "launchctl", "list"})
+	if err != nil {
+		return 0, fmt.Errorf("launchctl list on %s: %w", device.Name, err)
+	}
+	if pid := parseLaunchctlPID(output, bundleID); pid > 0 {
+		return pid, nil
+	}
+	return 0, fmt.Errorf("%s is not running on %s", bundleID, device.Name)
+}
+
+// parseLaunchctlPID finds the app's PID in `launchctl list` output (PID,
+// status and label columns; "-" for jobs that aren't running).
+func parseLaunchctlPID(output []byte, bundleID string) int {
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		label := strings.TrimPrefix(fields[2], "UIKitApplication:")
+		if label != bundleID && !strings.HasPrefix(label, bundleID+"[") {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			return pid
+		}
+	}
+	return 0
+}
+
 func (m *Manager) Terminate(ctx context.Context, device *Device, bundleID string) error {
+	if device.Type == DeviceTypePhysical {
+		return m.terminatePhysical(ctx, device, bundleID)
+	}
+
 	m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "terminate", device.UDID, bundleID})
 	return nil
 }
@@ -160,6 +373,53 @@ func (m *Manager) Delete(ctx context.Context, device *Device) error {
 	return err
 }
 
+// Erase resets a simulator's contents and settings. It must be shut down.
+func (m *Manager) Erase(ctx context.Context, device *Device) error {
+	if device.Type == DeviceTypePhysical {
+		return fmt.Errorf("cannot erase physical device %s", device.Name)
+	}
+
+	_, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "erase", device.UDID})
+	if err != nil {
+		return fmt.Errorf("erase %s: %w", device.Name, err)
+	}
+	return nil
+}
+
+// EraseAll erases every simulator. They must all be shut down.
+func (m *Manager) EraseAll(ctx context.Context) error {
+	_, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "erase", "all"})
+	return err
+}
+
+// Clone creates a copy of a shut down simulator, including its installed
+// apps and data, and returns the new simulator's UDID.
+func (m *Manager) Clone(ctx context.Context, device *Device, name string) (string, error) {
+	if device.Type == DeviceTypePhysical {
+		return "", fmt.Errorf("cannot clone physical device %s", device.Name)
+	}
+
+	output, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "clone", device.UDID, name})
+	if err != nil {
+		return "", fmt.Errorf("clone %s: %w", device.Name, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Rename changes a simulator's name.
+func (m *Manager) Rename(ctx context.Context, device *Device, name string) error {
+	if device.Type == DeviceTypePhysical {
+		return fmt.Errorf("cannot rename physical device %s", device.Name)
+	}
+
+	_, err := m.runner.RunSilent(ctx, "xcrun", []string{"simctl", "rename", device.UDID, name})
+	if err != nil {
+		return fmt.Errorf("rename %s: %w", device.Name, err)
+	}
+	device.Name = name
+	return nil
+}
+
 type DeviceTypeInfo struct {
 	Identifier string
 	Name       string
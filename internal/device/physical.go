@@ -0,0 +1,149 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// devicectlArgs builds an `xcrun devicectl` invocation that writes its JSON
+// result to stdout instead of a file. The output flags go before the
+// subcommand: anything after a launched app's bundle ID is passed to the app.
+func devicectlArgs(args ...string) []string {
+	return append([]string{"devicectl", "--quiet", "--json-output", "/dev/stdout"}, args...)
+}
+
+func (m *Manager) listPhysical(ctx context.Context) ([]*Device, error) {
+	output, err := m.runner.RunSilent(ctx, "xcrun", devicectlArgs("list", "devices"))
+	if err != nil {
+		return nil, fmt.Errorf("devicectl list: %w", err)
+	}
+	return parsePhysicalDevices(output), nil
+}
+
+// parsePhysicalDevices reads `devicectl list devices` JSON output.
+func parsePhysicalDevices(data []byte) []*Device {
+	var devices []*Device
+
+	gjson.GetBytes(data, "result.devices").ForEach(func(_, dev gjson.Result) bool {
+		if reality := dev.Get("hardwareProperties.reality").String(); reality != "" && reality != "physical" {
+			return true
+		}
+
+		udid := dev.Get("hardwareProperties.udid").String()
+		if udid == "" {
+			udid = dev.Get("identifier").String()
+		}
+
+		state := StateDisconnected
+		if dev.Get("connectionProperties.tunnelState").String() == "connected" {
+			state = StateConnected
+		}
+
+		devices = append(devices, &Device{
+			UDID:        udid,
+			Name:        dev.Get("deviceProperties.name").String(),
+			Type:        DeviceTypePhysical,
+			Platform:    platformFromHardware(dev.Get("hardwareProperties.platform").String()),
+			OSVersion:   dev.Get("deviceProperties.osVersionNumber").String(),
+			State:       state,
+			IsAvailable: dev.Get("connectionProperties.pairingState").String() == "paired",
+		})
+		return true
+	})
+
+	return devices
+}
+
+func platformFromHardware(platform string) Platform {
+	switch strings.ToLower(platform) {
+	case "ios", "ipados":
+		return PlatformIOS
+	case "watchos":
+		return PlatformWatchOS
+	case "tvos":
+		return PlatformTVOS
+	case "xros", "visionos":
+		return PlatformVisionOS
+	case "macos":
+		return PlatformMacOS
+	default:
+		return Platform("unknown")
+	}
+}
+
+func (m *Manager) installPhysical(ctx context.Context, device *Device, appPath string) error {
+	_, err := m.runner.RunSilent(ctx, "xcrun", devicectlArgs("device", "install", "app", "--device", device.UDID, appPath))
+	if err != nil {
+		return fmt.Errorf("install on %s: %w", device.Name, err)
+	}
+	return nil
+}
+
+func (m *Manager) launchPhysical(ctx context.Context, device *Device, bundleID string, opts LaunchOptions) (int, error) {
+	cmdArgs := devicectlLaunchArgs(device, bundleID, opts, false)
+
+	output, err := m.runner.RunSilent(ctx, "xcrun", devicectlArgs(cmdArgs...))
+	if err != nil {
+		return 0, fmt.Errorf("launch %s: %w", bundleID, err)
+	}
+
+	return int(gjson.GetBytes(output, "result.process.processIdentifier").Int()), nil
+}
+
+// terminatePhysical stops every process running from the app's bundle.
+func (m *Manager) terminatePhysical(ctx context.Context, device *Device, bundleID string) error {
+	pids, err := m.physicalAppPIDs(ctx, device, bundleID)
+	if err != nil {
+		return err
+	}
+
+	for _, pid := range pids {
+		_, _ = m.runner.RunSilent(ctx, "xcrun", devicectlArgs("device", "process", "terminate", "--device", device.UDID, "--pid", strconv.Itoa(pid)))
+	}
+	return nil
+}
+
+// physicalAppPIDs returns the PIDs of the processes running from the app's
+// bundle on a physical device.
+func (m *Manager) physicalAppPIDs(ctx context.Context, device *Device, bundleID string) ([]int, error) {
+	apps, err := m.runner.RunSilent(ctx, "xcrun", devicectlArgs("device", "info", "apps", "--device", device.UDID, "--bundle-id", bundleID))
+	if err != nil {
+		return nil, fmt.Errorf("devicectl apps: %w", err)
+	}
+
+	procs, err := m.runner.RunSilent(ctx, "xcrun", devicectlArgs("device", "info", "processes", "--device", device.UDID))
+	if err != nil {
+		return nil, fmt.Errorf("devicectl processes: %w", err)
+	}
+
+	return matchAppProcesses(apps, procs), nil
+}
+
+// matchAppProcesses returns the PIDs from `devicectl device info processes`
+// whose executable lives inside an app listed by `devicectl device info apps`.
+func matchAppProcesses(appsJSON, processesJSON []byte) []int {
+	var bundles []string
+	gjson.GetBytes(appsJSON, "result.apps").ForEach(func(_, app gjson.Result) bool {
+		if url := app.Get("url").String(); url != "" {
+			bundles = append(bundles, strings.TrimSuffix(url, "/")+"/")
+		}
+		return true
+	})
+
+	var pids []int
+	gjson.GetBytes(processesJSON, "result.runningProcesses").ForEach(func(_, proc gjson.Result) bool {
+		exe := proc.Get("executable").String()
+		for _, b := range bundles {
+			if strings.HasPrefix(exe, b) {
+				pids = append(pids, int(proc.Get("processIdentifier").Int()))
+				break
+			}
+		}
+		return true
+	})
+	return pids
+}
@@ -0,0 +1,62 @@
+package device
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParsePhysicalDevices(t *testing.T) {
+	data, err := os.ReadFile("testdata/devicectl_list_devices.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Device{
+		{
+			UDID:        "00008110-001A2B3C4D5E801E",
+			Name:        "Dev iPhone",
+			Type:        DeviceTypePhysical,
+			Platform:    PlatformIOS,
+			OSVersion:   "17.4.1",
+			State:       StateConnected,
+			IsAvailable: true,
+		},
+		{
+			UDID:      "00008103-000C1D2E3F40001E",
+			Name:      "Test iPad",
+			Type:      DeviceTypePhysical,
+			Platform:  PlatformIOS,
+			OSVersion: "17.2",
+			State:     StateDisconnected,
+		},
+		{
+			// No hardware UDID: falls back to the CoreDevice identifier.
+			UDID:        "0F1E2D3C-4B5A-4968-8776-655443322110",
+			Name:        "Dev Apple Watch",
+			Type:        DeviceTypePhysical,
+			Platform:    PlatformWatchOS,
+			OSVersion:   "10.4",
+			State:       StateDisconnected,
+			IsAvailable: true,
+		},
+		// The virtual device is skipped.
+	}
+
+	got := parsePhysicalDevices(data)
+	if len(got) != len(want) {
+		t.Fatalf("got %d devices, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if *got[i] != want[i] {
+			t.Errorf("device %d:\n got %+v\nwant %+v", i, *got[i], want[i])
+		}
+	}
+}
+
+func TestParsePhysicalDevicesEmpty(t *testing.T) {
+	for _, data := range []string{"", "{}", `{"result":{"devices":[]}}`} {
+		if got := parsePhysicalDevices([]byte(data)); len(got) != 0 {
+			t.Errorf("parsePhysicalDevices(%q) = %v, want none", data, got)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package device
 
+import "fmt"
+
 type Platform string
 
 const (
@@ -24,6 +26,10 @@ const (
 	StateBooted       DeviceState = "Booted"
 	StateBooting      DeviceState = "Booting"
 	StateShuttingDown DeviceState = "Shutting Down"
+
+	// Physical devices are either reachable over a CoreDevice tunnel or not.
+	StateConnected    DeviceState = "Connected"
+	StateDisconnected DeviceState = "Disconnected"
 )
 
 type Device struct {
@@ -34,5 +40,31 @@ type Device struct {
 	OSVersion   string      `json:"os_version"`
 	State       DeviceState `json:"state"`
 	IsAvailable bool        `json:"is_available"`
+
+	// CoreSimulator identifiers; simulators only.
+	DeviceTypeID string `json:"device_type_id,omitempty"`
+	RuntimeID    string `json:"runtime_id,omitempty"`
+}
+
+// Destination returns the xcodebuild -destination specifier for the device.
+func (d *Device) Destination() string {
+	var name string
+	switch d.Platform {
+	case PlatformMacOS:
+		return "platform=macOS"
+	case PlatformWatchOS:
+		name = "watchOS"
+	case PlatformTVOS:
+		name = "tvOS"
+	case PlatformVisionOS:
+		name = "visionOS"
+	default:
+		name = "iOS"
+	}
+
+	if d.Type == DeviceTypeSimulator {
+		name += " Simulator"
+	}
+	return fmt.Sprintf("platform=%s,id=%s", name, d.UDID)
 }
 
@@ -0,0 +1,195 @@
+package device
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Polling intervals for WaitForState: the first check comes quickly, then
+// the interval doubles up to the maximum.
+const (
+	minPollInterval = 250 * time.Millisecond
+	maxPollInterval = 2 * time.Second
+)
+
+// ParseState parses a state name as given on the command line, e.g.
+// "booted" or "shutting-down".
+func ParseState(s string) (DeviceState, error) {
+	name := strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(s))
+	for _, state := range []DeviceState{StateShutdown, StateBooted, StateBooting, StateShuttingDown, StateConnected, StateDisconnected} {
+		if strings.ToLower(strings.ReplaceAll(string(state), " ", "")) == name {
+			return state, nil
+		}
+	}
+	return "", fmt.Errorf("unknown state %q (valid: booted, booting, shutdown, shutting-down, connected, disconnected)", s)
+}
+
+// WaitForState polls until device is in state, backing off between checks,
+// and fails once timeout has passed. device.State is updated on success.
+func (m *Manager) WaitForState(ctx context.Context, device *Device, state DeviceState, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	interval := minPollInterval
+	for {
+		current, err := m.state(ctx, device)
+		switch {
+		case err == nil:
+			device.State = current
+			if current == state {
+				return nil
+			}
+		case ctx.Err() == nil:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%s is still %s after %s (waiting for %s)", device.Name, device.State, timeout, state)
+			}
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, maxPollInterval)
+	}
+}
+
+// state returns device's current state.
+func (m *Manager) state(ctx context.Context, device *Device) (DeviceState, error) {
+	var devices []*Device
+	var err error
+	if device.Type == DeviceTypePhysical {
+		devices, err = m.listPhysical(ctx)
+	} else {
+		devices, err = m.listSimulators(ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	for _, d := range devices {
+		if d.UDID == device.UDID {
+			return d.State, nil
+		}
+	}
+	if device.Type == DeviceTypePhysical {
+		return StateDisconnected, nil
+	}
+	return "", fmt.Errorf("%s no longer exists", device.Name)
+}
+
+// Event types reported by Watch.
+const (
+	EventCreated = "created"
+	EventDeleted = "deleted"
+	EventState   = "state"
+)
+
+// Event is a simulator being created or deleted, or changing state.
+type Event struct {
+	Time      time.Time   `json:"time"`
+	Type      string      `json:"type"`
+	UDID      string      `json:"udid"`
+	Name      string      `json:"name"`
+	Platform  Platform    `json:"platform"`
+	OSVersion string      `json:"os_version"`
+	State     DeviceState `json:"state,omitempty"`
+	PrevState DeviceState `json:"previous_state,omitempty"`
+}
+
+// Watch polls the simulator list every interval and sends an Event for
+// each difference from the previous poll. Both channels are closed when
+// ctx is done or listing fails; the error, if any, is sent first.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		var prev map[string]*Device
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			devices, err := m.listSimulators(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					errs <- err
+				}
+				return
+			}
+
+			current := make(map[string]*Device, len(devices))
+			for _, d := range devices {
+				current[d.UDID] = d
+			}
+			// The first poll is the baseline, not a change.
+			if prev != nil {
+				for _, ev := range diffDevices(prev, devices, time.Now()) {
+					select {
+					case events <- ev:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			prev = current
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events, errs
+}
+
+// diffDevices returns the events turning prev into devices, deletions last.
+func diffDevices(prev map[string]*Device, devices []*Device, now time.Time) []Event {
+	var events []Event
+	seen := make(map[string]bool, len(devices))
+
+	for _, d := range devices {
+		seen[d.UDID] = true
+		old, ok := prev[d.UDID]
+		switch {
+		case !ok:
+			events = append(events, newEvent(EventCreated, d, now))
+		case old.State != d.State:
+			ev := newEvent(EventState, d, now)
+			ev.PrevState = old.State
+			events = append(events, ev)
+		}
+	}
+
+	var deleted []Event
+	for udid, d := range prev {
+		if !seen[udid] {
+			ev := newEvent(EventDeleted, d, now)
+			ev.State = ""
+			deleted = append(deleted, ev)
+		}
+	}
+	sort.Slice(deleted, func(i, j int) bool { return deleted[i].Name < deleted[j].Name })
+	return append(events, deleted...)
+}
+
+func newEvent(typ string, d *Device, now time.Time) Event {
+	return Event{
+		Time:      now,
+		Type:      typ,
+		UDID:      d.UDID,
+		Name:      d.Name,
+		Platform:  d.Platform,
+		OSVersion: d.OSVersion,
+		State:     d.State,
+	}
+}
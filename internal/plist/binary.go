@@ -0,0 +1,275 @@
+package plist
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf16"
+)
+
+const binaryMagic = "bplist00"
+
+// appleEpoch is the reference date for binary plist dates.
+var appleEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type binaryDecoder struct {
+	data       []byte
+	offsets    []uint64
+	refSize    int
+	inProgress map[uint64]bool
+}
+
+func decodeBinary(data []byte) (any, error) {
+	if len(data) < len(binaryMagic)+32 {
+		return nil, fmt.Errorf("plist: binary plist too short")
+	}
+
+	trailer := data[len(data)-32:]
+	offsetSize := int(trailer[6])
+	refSize := int(trailer[7])
+	numObjects := binary.BigEndian.Uint64(trailer[8:])
+	topObject := binary.BigEndian.Uint64(trailer[16:])
+	tableOffset := binary.BigEndian.Uint64(trailer[24:])
+
+	if offsetSize < 1 || offsetSize > 8 || refSize < 1 || refSize > 8 {
+		return nil, fmt.Errorf("plist: invalid binary trailer")
+	}
+	tableEnd := uint64(len(data) - 32)
+	if numObjects == 0 || topObject >= numObjects || tableOffset >= tableEnd ||
+		numObjects > (tableEnd-tableOffset)/uint64(offsetSize) {
+		return nil, fmt.Errorf("plist: invalid binary trailer")
+	}
+
+	d := &binaryDecoder{
+		data:       data,
+		offsets:    make([]uint64, numObjects),
+		refSize:    refSize,
+		inProgress: make(map[uint64]bool),
+	}
+	for i := range d.offsets {
+		start := tableOffset + uint64(i*offsetSize)
+		d.offsets[i] = readUint(data[start : start+uint64(offsetSize)])
+		if d.offsets[i] >= tableOffset {
+			return nil, fmt.Errorf("plist: object %d offset out of range", i)
+		}
+	}
+
+	return d.object(topObject)
+}
+
+func (d *binaryDecoder) object(ref uint64) (any, error) {
+	if ref >= uint64(len(d.offsets)) {
+		return nil, fmt.Errorf("plist: object reference %d out of range", ref)
+	}
+	if d.inProgress[ref] {
+		return nil, fmt.Errorf("plist: object %d contains itself", ref)
+	}
+	d.inProgress[ref] = true
+	defer delete(d.inProgress, ref)
+
+	off := d.offsets[ref]
+	marker := d.data[off]
+	kind, info := marker>>4, marker&0x0f
+	off++
+
+	switch kind {
+	case 0x0:
+		switch info {
+		case 0x8:
+			return false, nil
+		case 0x9:
+			return true, nil
+		}
+		return nil, nil
+
+	case 0x1:
+		b, err := d.bytes(off, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		return binaryInt(b), nil
+
+	case 0x2:
+		b, err := d.bytes(off, 1<<info)
+		if err != nil {
+			return nil, err
+		}
+		switch len(b) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+		}
+		return nil, fmt.Errorf("plist: unsupported real size %d", len(b))
+
+	case 0x3:
+		b, err := d.bytes(off, 8)
+		if err != nil {
+			return nil, err
+		}
+		secs := math.Float64frombits(binary.BigEndian.Uint64(b))
+		return appleEpoch.Add(time.Duration(secs * float64(time.Second))), nil
+
+	case 0x4:
+		n, off, err := d.count(info, off)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(off, n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+
+	case 0x5:
+		n, off, err := d.count(info, off)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(off, n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case 0x6:
+		n, off, err := d.count(info, off)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(off, n*2)
+		if err != nil {
+			return nil, err
+		}
+		units := make([]uint16, n)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(b[i*2:])
+		}
+		return string(utf16.Decode(units)), nil
+
+	case 0x8:
+		b, err := d.bytes(off, int(info)+1)
+		if err != nil {
+			return nil, err
+		}
+		return UID(readUint(b)), nil
+
+	case 0xa, 0xc:
+		n, off, err := d.count(info, off)
+		if err != nil {
+			return nil, err
+		}
+		refs, err := d.refs(off, n)
+		if err != nil {
+			return nil, err
+		}
+		arr := make([]any, 0, n)
+		for _, r := range refs {
+			v, err := d.object(r)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+
+	case 0xd:
+		n, off, err := d.count(info, off)
+		if err != nil {
+			return nil, err
+		}
+		refs, err := d.refs(off, n*2)
+		if err != nil {
+			return nil, err
+		}
+		dict := make(map[string]any, n)
+		for i := 0; i < n; i++ {
+			k, err := d.object(refs[i])
+			if err != nil {
+				return nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("plist: dictionary key is %T, not a string", k)
+			}
+			if dict[key], err = d.object(refs[n+i]); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+
+	return nil, fmt.Errorf("plist: unknown object marker 0x%02x", marker)
+}
+
+// count decodes an object's length: the low nibble of the marker, or a
+// following integer object when the nibble is 0xf.
+func (d *binaryDecoder) count(info byte, off uint64) (int, uint64, error) {
+	if info != 0xf {
+		return int(info), off, nil
+	}
+
+	b, err := d.bytes(off, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if b[0]>>4 != 0x1 {
+		return 0, 0, fmt.Errorf("plist: invalid length marker 0x%02x", b[0])
+	}
+	size := 1 << (b[0] & 0x0f)
+	nb, err := d.bytes(off+1, size)
+	if err != nil {
+		return 0, 0, err
+	}
+	n := readUint(nb)
+	if n > uint64(len(d.data)) {
+		return 0, 0, fmt.Errorf("plist: length %d out of range", n)
+	}
+	return int(n), off + 1 + uint64(size), nil
+}
+
+func (d *binaryDecoder) refs(off uint64, n int) ([]uint64, error) {
+	b, err := d.bytes(off, n*d.refSize)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]uint64, n)
+	for i := range refs {
+		refs[i] = readUint(b[i*d.refSize : (i+1)*d.refSize])
+	}
+	return refs, nil
+}
+
+func (d *binaryDecoder) bytes(off uint64, n int) ([]byte, error) {
+	if n < 0 || off > uint64(len(d.data)) || uint64(n) > uint64(len(d.data))-off {
+		return nil, fmt.Errorf("plist: object at offset %d runs past end of data", off)
+	}
+	return d.data[off : off+uint64(n)], nil
+}
+
+// binaryInt decodes a big-endian integer. 1, 2 and 4 byte integers are
+// unsigned, 8 byte integers are signed, and 16 byte integers hold a
+// 64-bit value in their low half.
+func binaryInt(b []byte) any {
+	if len(b) == 16 {
+		hi := binary.BigEndian.Uint64(b[:8])
+		lo := binary.BigEndian.Uint64(b[8:])
+		if hi == 0 && lo > math.MaxInt64 {
+			return lo
+		}
+		return int64(lo)
+	}
+	if len(b) == 8 {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return int64(readUint(b))
+}
+
+func readUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
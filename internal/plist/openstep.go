@@ -0,0 +1,252 @@
+package plist
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type openStepParser struct {
+	data []byte
+	pos  int
+}
+
+func decodeOpenStep(data []byte) (any, error) {
+	p := &openStepParser{data: data}
+	if len(data) >= 3 && string(data[:3]) == "\xef\xbb\xbf" {
+		p.pos = 3
+	}
+
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+
+	// A top-level "key = value;" sequence without braces is a strings file.
+	if key, ok := v.(string); ok && p.peek() == '=' {
+		return p.dictBody(key, 0)
+	}
+
+	if c := p.peek(); c != 0 {
+		return nil, p.errorf("unexpected %q after value", c)
+	}
+	return v, nil
+}
+
+func (p *openStepParser) value() (any, error) {
+	switch c := p.peek(); c {
+	case 0:
+		return nil, p.errorf("unexpected end of input")
+
+	case '{':
+		p.pos++
+		if p.peek() == '}' {
+			p.pos++
+			return map[string]any{}, nil
+		}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		return p.dictBody(key, '}')
+
+	case '(':
+		p.pos++
+		arr := []any{}
+		for {
+			if p.peek() == ')' {
+				p.pos++
+				return arr, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+
+			switch p.peek() {
+			case ',':
+				p.pos++
+			case ')':
+			default:
+				return nil, p.errorf("expected ',' or ')' in array")
+			}
+		}
+
+	case '<':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] != '>' {
+			p.pos++
+		}
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unterminated data")
+		}
+		digits := strings.Join(strings.Fields(string(p.data[start:p.pos])), "")
+		p.pos++
+		b, err := hex.DecodeString(digits)
+		if err != nil {
+			return nil, p.errorf("invalid data: %v", err)
+		}
+		return b, nil
+
+	case '"', '\'':
+		return p.quoted(c)
+
+	default:
+		start := p.pos
+		for p.pos < len(p.data) && isUnquoted(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			return nil, p.errorf("unexpected %q", c)
+		}
+		return string(p.data[start:p.pos]), nil
+	}
+}
+
+// dictBody parses "= value; key = value; ..." after the first key, up to
+// the closing byte (0 for end of input).
+func (p *openStepParser) dictBody(key string, closing byte) (map[string]any, error) {
+	dict := make(map[string]any)
+	for {
+		if p.peek() != '=' {
+			return nil, p.errorf("expected '=' after key %q", key)
+		}
+		p.pos++
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		dict[key] = v
+
+		if p.peek() != ';' {
+			return nil, p.errorf("expected ';' after value for %q", key)
+		}
+		p.pos++
+
+		if c := p.peek(); c == closing {
+			if closing != 0 {
+				p.pos++
+			}
+			return dict, nil
+		}
+
+		if key, err = p.key(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *openStepParser) key() (string, error) {
+	v, err := p.value()
+	if err != nil {
+		return "", err
+	}
+	key, ok := v.(string)
+	if !ok {
+		return "", p.errorf("dictionary key is %T, not a string", v)
+	}
+	return key, nil
+}
+
+func (p *openStepParser) quoted(quote byte) (string, error) {
+	p.pos++
+	var b strings.Builder
+
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+
+		switch c {
+		case quote:
+			return b.String(), nil
+
+		case '\\':
+			if p.pos >= len(p.data) {
+				return "", p.errorf("unterminated escape")
+			}
+			e := p.data[p.pos]
+			p.pos++
+
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'a':
+				b.WriteByte('\a')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'v':
+				b.WriteByte('\v')
+			case 'U':
+				end := min(p.pos+4, len(p.data))
+				r, err := strconv.ParseUint(string(p.data[p.pos:end]), 16, 32)
+				if err != nil || end-p.pos != 4 {
+					return "", p.errorf("invalid \\U escape")
+				}
+				p.pos = end
+				b.WriteRune(rune(r))
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				end := p.pos - 1
+				for end < len(p.data) && end < p.pos+2 && p.data[end] >= '0' && p.data[end] <= '7' {
+					end++
+				}
+				r, _ := strconv.ParseUint(string(p.data[p.pos-1:end]), 8, 8)
+				p.pos = end
+				b.WriteByte(byte(r))
+			default:
+				b.WriteByte(e)
+			}
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+// peek skips whitespace and comments and returns the next byte, or 0 at
+// end of input.
+func (p *openStepParser) peek() byte {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '*':
+			end := strings.Index(string(p.data[p.pos+2:]), "*/")
+			if end < 0 {
+				p.pos = len(p.data)
+			} else {
+				p.pos += end + 4
+			}
+		default:
+			return c
+		}
+	}
+	return 0
+}
+
+func (p *openStepParser) errorf(format string, args ...any) error {
+	line := 1 + strings.Count(string(p.data[:min(p.pos, len(p.data))]), "\n")
+	return fmt.Errorf("plist: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func isUnquoted(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("_$+/:.-", c) >= 0 || c >= utf8.RuneSelf
+}
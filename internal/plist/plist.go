@@ -0,0 +1,91 @@
+// Package plist decodes property lists in the XML, binary (bplist00) and
+// OpenStep formats.
+//
+// Values decode to map[string]any, []any, string, int64 (uint64 for
+// integers above math.MaxInt64), float64, bool, time.Time, []byte and UID.
+package plist
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+// UID is a keyed-archiver object reference, only found in binary plists.
+type UID uint64
+
+// Format identifies a property list encoding.
+type Format int
+
+const (
+	FormatXML Format = iota
+	FormatBinary
+	FormatOpenStep
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatXML:
+		return "xml"
+	case FormatBinary:
+		return "binary"
+	default:
+		return "openstep"
+	}
+}
+
+// Detect reports the format of data without decoding it.
+func Detect(data []byte) Format {
+	if bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return FormatBinary
+	}
+
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	for _, prefix := range []string{"<?xml", "<!DOCTYPE", "<plist"} {
+		if bytes.HasPrefix(trimmed, []byte(prefix)) {
+			return FormatXML
+		}
+	}
+	return FormatOpenStep
+}
+
+// Decode parses a property list in any supported format.
+func Decode(data []byte) (any, error) {
+	switch Detect(data) {
+	case FormatBinary:
+		return decodeBinary(data)
+	case FormatXML:
+		return decodeXML(data)
+	default:
+		return decodeOpenStep(data)
+	}
+}
+
+// ReadFile decodes the property list at path.
+func ReadFile(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return v, nil
+}
+
+// ReadDict decodes the property list at path and requires a dictionary at
+// the top level, as in Info.plist.
+func ReadDict(path string) (map[string]any, error) {
+	v, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dict, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: top-level value is %T, not a dictionary", path, v)
+	}
+	return dict, nil
+}
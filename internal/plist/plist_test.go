@@ -0,0 +1,260 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// appKeys are the Info.plist values run.ReadAppInfo relies on, identical in
+// every fixture.
+var appKeys = map[string]any{
+	"CFBundleIdentifier":         "com.example.MyApp",
+	"CFBundleExecutable":         "MyApp",
+	"CFBundleName":               "MyApp",
+	"CFBundleDisplayName":        "Café ☕",
+	"CFBundleShortVersionString": "1.2.0",
+	"MinimumOSVersion":           "17.0",
+}
+
+func TestReadDict(t *testing.T) {
+	typed := map[string]any{
+		"CFBundleVersion":      "42",
+		"UIDeviceFamily":       []any{int64(1), int64(2)},
+		"UIRequiresFullScreen": true,
+		"UIStatusBarHidden":    false,
+		"LSRequiresIPhoneOS":   true,
+		"DTPlatformBuild":      "",
+		"BuildMachineOSBuild":  "23E224",
+		"ScaleFactor":          2.5,
+		"BuildDate":            time.Date(2024, 5, 1, 17, 15, 0, 0, time.UTC),
+		"IconHash":             []byte{0x00, 0x01, 0xfe, 0xff},
+		"BigNumber":            uint64(18446744073709551615),
+		"Negative":             int64(-7),
+	}
+
+	tests := []struct {
+		file   string
+		format Format
+		extra  map[string]any
+	}{
+		{"Info.binary.plist", FormatBinary, typed},
+		{"Info.xml.plist", FormatXML, typed},
+		{"Info.openstep.plist", FormatOpenStep, map[string]any{
+			// OpenStep has no numbers or booleans: everything is a string.
+			"CFBundleVersion": "42",
+			"UIDeviceFamily":  []any{"1", "2"},
+			"IconHash":        []byte{0x00, 0x01, 0xfe, 0xff},
+			"Escapes":         "tab\there \"quoted\" back\\slash\nnew line A",
+			"Single Quoted":   "it's",
+			"Empty":           map[string]any{},
+			"Nested":          map[string]any{"List": []any{"a", "b c", []any{}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			path := "testdata/" + tt.file
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Detect(data); got != tt.format {
+				t.Errorf("Detect = %v, want %v", got, tt.format)
+			}
+
+			dict, err := ReadDict(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := make(map[string]any)
+			for k, v := range appKeys {
+				want[k] = v
+			}
+			for k, v := range tt.extra {
+				want[k] = v
+			}
+			for k := range dict {
+				if _, ok := want[k]; !ok {
+					t.Errorf("unexpected key %q", k)
+				}
+			}
+			for k, w := range want {
+				if got := dict[k]; !reflect.DeepEqual(got, w) {
+					t.Errorf("%s = %#v, want %#v", k, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestReadDictNotDictionary(t *testing.T) {
+	path := t.TempDir() + "/array.plist"
+	if err := os.WriteFile(path, []byte("(a, b)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadDict(path); err == nil {
+		t.Error("ReadDict accepted a top-level array")
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	// Cutting anywhere between the start and end markers must fail. Shorter
+	// prefixes can be valid plists of their own ("b" is an OpenStep string)
+	// and the XML decoder doesn't insist on the closing </plist>.
+	tests := []struct {
+		file       string
+		start, end string
+	}{
+		{"Info.binary.plist", "bplist00", ""},
+		{"Info.xml.plist", "<", "</dict>"},
+		{"Info.openstep.plist", "{", "}"},
+	}
+
+	for _, tt := range tests {
+		data, err := os.ReadFile("testdata/" + tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from := bytes.Index(data, []byte(tt.start)) + len(tt.start)
+		to := len(data)
+		if tt.end != "" {
+			to = bytes.LastIndex(data, []byte(tt.end)) + len(tt.end) - 1
+		}
+		for n := from; n < to; n++ {
+			if _, err := Decode(data[:n]); err == nil {
+				t.Errorf("%s truncated to %d bytes decoded without error", tt.file, n)
+			}
+		}
+	}
+}
+
+// binaryPlist assembles a bplist00 from encoded objects, with one-byte
+// offsets and object references.
+func binaryPlist(objects ...[]byte) []byte {
+	data := []byte("bplist00")
+	var offsets []byte
+	for _, obj := range objects {
+		offsets = append(offsets, byte(len(data)))
+		data = append(data, obj...)
+	}
+	tableOffset := len(data)
+	data = append(data, offsets...)
+
+	trailer := make([]byte, 32)
+	trailer[6], trailer[7] = 1, 1
+	binary.BigEndian.PutUint64(trailer[8:], uint64(len(objects)))
+	binary.BigEndian.PutUint64(trailer[24:], uint64(tableOffset))
+	return append(data, trailer...)
+}
+
+func TestDecodeBinary(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    any
+		wantErr bool
+	}{
+		{
+			name: "utf-16 string",
+			data: binaryPlist([]byte{0x62, 0x00, 0x68, 0x00, 0xe9}),
+			want: "hé",
+		},
+		{
+			name: "surrogate pair",
+			data: binaryPlist([]byte{0x62, 0xd8, 0x3d, 0xde, 0x00}),
+			want: "😀",
+		},
+		{
+			name: "uid",
+			data: binaryPlist([]byte{0x80, 0x07}),
+			want: UID(7),
+		},
+		{
+			name: "shared value",
+			// [s, s]: an object referenced twice is not a cycle.
+			data: binaryPlist([]byte{0xa2, 0x01, 0x01}, []byte{0x51, 'x'}),
+			want: []any{"x", "x"},
+		},
+		{
+			name:    "array containing itself",
+			data:    binaryPlist([]byte{0xa1, 0x00}),
+			wantErr: true,
+		},
+		{
+			name:    "indirect cycle",
+			data:    binaryPlist([]byte{0xd1, 0x01, 0x02}, []byte{0x51, 'k'}, []byte{0xa1, 0x00}),
+			wantErr: true,
+		},
+		{
+			name:    "reference out of range",
+			data:    binaryPlist([]byte{0xa1, 0x05}),
+			wantErr: true,
+		},
+		{
+			name:    "non-string key",
+			data:    binaryPlist([]byte{0xd1, 0x01, 0x01}, []byte{0x10, 0x01}),
+			wantErr: true,
+		},
+		{
+			name:    "length past end",
+			data:    binaryPlist([]byte{0x5f, 0x10, 0x7f, 'a'}),
+			wantErr: true,
+		},
+		{
+			name:    "unknown marker",
+			data:    binaryPlist([]byte{0x70}),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decoded %#v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeOpenStepErrors(t *testing.T) {
+	for _, input := range []string{
+		`{ a = b }`,
+		`{ a b; }`,
+		`{ (a) = b; }`,
+		`"unterminated`,
+		`"bad \U12 escape"`,
+		`<0g>`,
+		`(a b)`,
+		`a = b; c`,
+		`{ a = b; } extra`,
+	} {
+		if v, err := Decode([]byte(input)); err == nil {
+			t.Errorf("Decode(%q) = %#v, want an error", input, v)
+		}
+	}
+}
+
+func TestDecodeStringsFile(t *testing.T) {
+	got, err := Decode([]byte("/* Localizable.strings */\n\"greeting\" = \"Hello\";\nfarewell = \"Bye\";\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{"greeting": "Hello", "farewell": "Bye"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func decodeXML(data []byte) (any, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		start, ok, err := nextElement(d)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("plist: no value in XML document")
+		}
+		if start.Name.Local == "plist" {
+			continue
+		}
+		return xmlValue(d, start)
+	}
+}
+
+// nextElement returns the next start element, skipping text, comments and
+// directives. ok is false when an end element or EOF comes first.
+func nextElement(d *xml.Decoder) (start xml.StartElement, ok bool, err error) {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return xml.StartElement{}, false, nil
+			}
+			return xml.StartElement{}, false, fmt.Errorf("plist: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t, true, nil
+		case xml.EndElement:
+			return xml.StartElement{}, false, nil
+		}
+	}
+}
+
+func xmlValue(d *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]any)
+		for {
+			key, ok, err := nextElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return dict, nil
+			}
+			if key.Name.Local != "key" {
+				return nil, fmt.Errorf("plist: expected <key> in <dict>, got <%s>", key.Name.Local)
+			}
+			name, err := xmlText(d)
+			if err != nil {
+				return nil, err
+			}
+
+			value, ok, err := nextElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("plist: missing value for key %q", name)
+			}
+			if dict[name], err = xmlValue(d, value); err != nil {
+				return nil, err
+			}
+		}
+
+	case "array":
+		arr := []any{}
+		for {
+			elem, ok, err := nextElement(d)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return arr, nil
+			}
+			v, err := xmlValue(d, elem)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, fmt.Errorf("plist: %w", err)
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	text, err := xmlText(d)
+	if err != nil {
+		return nil, err
+	}
+
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+
+	case "integer":
+		return parseInteger(strings.TrimSpace(text))
+
+	case "real":
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("plist: invalid real %q", text)
+		}
+		return f, nil
+
+	case "date":
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("plist: invalid date %q", text)
+		}
+		return t, nil
+
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("plist: invalid data: %w", err)
+		}
+		return b, nil
+
+	default:
+		return nil, fmt.Errorf("plist: unknown element <%s>", start.Name.Local)
+	}
+}
+
+// xmlText reads character data up to the end of the current element.
+func xmlText(d *xml.Decoder) (string, error) {
+	var b strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", fmt.Errorf("plist: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.StartElement:
+			return "", fmt.Errorf("plist: unexpected <%s> in text", t.Name.Local)
+		case xml.EndElement:
+			return b.String(), nil
+		}
+	}
+}
+
+func parseInteger(s string) (any, error) {
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return i, nil
+	}
+	if u, err := strconv.ParseUint(s, 0, 64); err == nil {
+		return u, nil
+	}
+	return nil, fmt.Errorf("plist: invalid integer %q", s)
+}
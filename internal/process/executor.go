@@ -0,0 +1,101 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Command describes a single external tool invocation.
+type Command struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+	Env  []string `json:"env,omitempty"` // KEY=VALUE pairs added to the inherited environment
+}
+
+func (c Command) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+// Executor starts commands. Every Runner invocation goes through one, so
+// swapping it out (fakes, recorders) changes how the whole CLI talks to
+// xcrun, xcodebuild and swift.
+type Executor interface {
+	Start(ctx context.Context, cmd Command) (Process, error)
+}
+
+// Process is a started command. Stdout and Stderr must be drained before Wait.
+type Process interface {
+	Stdout() io.Reader
+	Stderr() io.Reader
+	Signal(sig os.Signal) error
+	Wait() error
+}
+
+// ExitError reports a non-zero exit from an Executor that doesn't run real
+// processes.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// ExitCode extracts the exit code from a Wait error (0 for nil, -1 if unknown).
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if coder, ok := err.(interface{ ExitCode() int }); ok {
+		return coder.ExitCode()
+	}
+	return -1
+}
+
+// ExecExecutor runs commands on the host with os/exec.
+type ExecExecutor struct{}
+
+func (ExecExecutor) Start(ctx context.Context, cmd Command) (Process, error) {
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
+	}
+
+	stdout, err := c.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+
+	stderr, err := c.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("stderr pipe: %w", err)
+	}
+
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+
+	return &execProcess{cmd: c, stdout: stdout, stderr: stderr}, nil
+}
+
+type execProcess struct {
+	cmd    *exec.Cmd
+	stdout io.Reader
+	stderr io.Reader
+}
+
+func (p *execProcess) Stdout() io.Reader { return p.stdout }
+func (p *execProcess) Stderr() io.Reader { return p.stderr }
+func (p *execProcess) Wait() error       { return p.cmd.Wait() }
+
+func (p *execProcess) Signal(sig os.Signal) error {
+	return p.cmd.Process.Signal(sig)
+}
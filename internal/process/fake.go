@@ -0,0 +1,105 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// FakeResponse is the canned result of a scripted command.
+type FakeResponse struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// FakeExecutor is a scripted Executor that maps command lines to canned
+// responses, so code built on Runner can be driven without Xcode.
+type FakeExecutor struct {
+	mu        sync.Mutex
+	responses map[string][]FakeResponse
+	calls     []Command
+}
+
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{responses: make(map[string][]FakeResponse)}
+}
+
+// On registers a response for a command line such as "xcrun simctl list devices -j".
+// A trailing " *" matches any remaining arguments. Responses registered for the
+// same line are returned in order and the last one repeats.
+func (f *FakeExecutor) On(cmdline string, resp FakeResponse) *FakeExecutor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[cmdline] = append(f.responses[cmdline], resp)
+	return f
+}
+
+// Calls returns every command started so far, in order.
+func (f *FakeExecutor) Calls() []Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Command(nil), f.calls...)
+}
+
+func (f *FakeExecutor) Start(ctx context.Context, cmd Command) (Process, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, cmd)
+
+	key := f.match(cmd.String())
+	if key == "" {
+		return nil, fmt.Errorf("fake: no response for %q", cmd.String())
+	}
+
+	queue := f.responses[key]
+	resp := queue[0]
+	if len(queue) > 1 {
+		f.responses[key] = queue[1:]
+	}
+
+	return &fakeProcess{
+		stdout:   strings.NewReader(resp.Stdout),
+		stderr:   strings.NewReader(resp.Stderr),
+		exitCode: resp.ExitCode,
+	}, nil
+}
+
+func (f *FakeExecutor) match(line string) string {
+	if _, ok := f.responses[line]; ok {
+		return line
+	}
+
+	best := ""
+	for key := range f.responses {
+		prefix, ok := strings.CutSuffix(key, " *")
+		if !ok {
+			continue
+		}
+		if (line == prefix || strings.HasPrefix(line, prefix+" ")) && len(key) > len(best) {
+			best = key
+		}
+	}
+	return best
+}
+
+type fakeProcess struct {
+	stdout   io.Reader
+	stderr   io.Reader
+	exitCode int
+}
+
+func (p *fakeProcess) Stdout() io.Reader          { return p.stdout }
+func (p *fakeProcess) Stderr() io.Reader          { return p.stderr }
+func (p *fakeProcess) Signal(sig os.Signal) error { return nil }
+
+func (p *fakeProcess) Wait() error {
+	if p.exitCode != 0 {
+		return &ExitError{Code: p.exitCode}
+	}
+	return nil
+}
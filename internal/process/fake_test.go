@@ -0,0 +1,66 @@
+package process
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFakeExecutorMatch(t *testing.T) {
+	f := NewFakeExecutor().
+		On("xcrun simctl list devices -j", FakeResponse{Stdout: "exact"}).
+		On("xcrun simctl *", FakeResponse{Stdout: "simctl"}).
+		On("xcrun simctl boot *", FakeResponse{Stdout: "boot"}).
+		On("xcrun *", FakeResponse{Stdout: "xcrun"})
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"xcrun simctl list devices -j", "xcrun simctl list devices -j"},
+		{"xcrun simctl list devices", "xcrun simctl *"},
+		{"xcrun simctl boot ABC", "xcrun simctl boot *"},
+		{"xcrun simctl boot", "xcrun simctl boot *"},
+		{"xcrun simctlx", "xcrun *"},
+		{"xcrun", "xcrun *"},
+		{"xcodebuild -list", ""},
+	}
+	for _, tt := range tests {
+		if got := f.match(tt.line); got != tt.want {
+			t.Errorf("match(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestFakeExecutorResponses(t *testing.T) {
+	f := NewFakeExecutor().
+		On("xcrun simctl bootstatus *", FakeResponse{Stdout: "booting"}).
+		On("xcrun simctl bootstatus *", FakeResponse{Stdout: "booted"}).
+		On("xcrun simctl delete X", FakeResponse{Stderr: "Invalid device: X", ExitCode: 164})
+	r := NewRunner(WithExecutor(f))
+	ctx := context.Background()
+
+	// Queued responses are served in order and the last one repeats.
+	for i, want := range []string{"booting", "booted", "booted"} {
+		out, err := r.RunSilent(ctx, "xcrun", []string{"simctl", "bootstatus", "A"})
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if string(out) != want {
+			t.Errorf("call %d = %q, want %q", i, out, want)
+		}
+	}
+
+	_, err := r.RunSilent(ctx, "xcrun", []string{"simctl", "delete", "X"})
+	if err == nil || err.Error() != "exit status 164: Invalid device: X" {
+		t.Errorf("delete error = %v, want exit 164 with stderr", err)
+	}
+
+	if _, err := r.RunSilent(ctx, "swift", []string{"build"}); err == nil {
+		t.Error("unscripted command succeeded")
+	}
+
+	calls := f.Calls()
+	if len(calls) != 5 || calls[4].String() != "swift build" {
+		t.Errorf("calls = %v", calls)
+	}
+}
@@ -0,0 +1,57 @@
+package process
+
+import (
+	"slices"
+	"sync"
+)
+
+// Plan collects the commands a dry run would have executed.
+type Plan struct {
+	mu       sync.Mutex
+	commands []Command
+}
+
+func (p *Plan) Add(cmd Command) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.commands = append(p.commands, cmd)
+}
+
+// Commands returns the planned commands in execution order.
+func (p *Plan) Commands() []Command {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Command(nil), p.commands...)
+}
+
+// IsQuery reports whether cmd only reads state. Queries still run during a
+// dry run so project, scheme and device resolution work; everything else is
+// added to the plan instead.
+func IsQuery(cmd Command) bool {
+	switch cmd.Name {
+	case "xcodebuild":
+		for _, arg := range cmd.Args {
+			switch arg {
+			case "-list", "-showBuildSettings", "-version", "-showsdks":
+				return true
+			}
+		}
+	case "xcrun":
+		return hasArgPrefix(cmd.Args, "simctl", "list") ||
+			hasArgPrefix(cmd.Args, "devicectl", "list") ||
+			hasArgPrefix(cmd.Args, "devicectl", "device", "info") ||
+			hasArgPrefix(cmd.Args, "xcresulttool", "get") ||
+			hasArgPrefix(cmd.Args, "atos") ||
+			(len(cmd.Args) > 3 && hasArgPrefix(cmd.Args, "simctl", "spawn") && hasArgPrefix(cmd.Args[3:], "launchctl", "list"))
+	case "ps":
+		return true
+	case "swift":
+		return hasArgPrefix(cmd.Args, "package", "describe") ||
+			slices.Contains(cmd.Args, "--show-bin-path")
+	}
+	return false
+}
+
+func hasArgPrefix(args []string, prefix ...string) bool {
+	return len(args) >= len(prefix) && slices.Equal(args[:len(prefix)], prefix)
+}
@@ -0,0 +1,275 @@
+package process
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recording is one captured invocation, stored as NNNN.json in a fixture directory.
+type Recording struct {
+	Seq        int            `json:"seq"`
+	Command    Command        `json:"command"`
+	StartedAt  time.Time      `json:"started_at"`
+	Duration   time.Duration  `json:"duration"`
+	Output     []RecordedLine `json:"output"`
+	ExitCode   int            `json:"exit_code"`
+	Error      string         `json:"error,omitempty"`
+	StartError string         `json:"start_error,omitempty"`
+	Canceled   bool           `json:"canceled,omitempty"`
+}
+
+// RecordedLine is a line of output and when it arrived relative to start.
+type RecordedLine struct {
+	Stream  string        `json:"stream"`
+	Content string        `json:"content"`
+	Offset  time.Duration `json:"offset"`
+}
+
+// RecordingExecutor wraps another Executor and writes every invocation to dir.
+type RecordingExecutor struct {
+	inner Executor
+	dir   string
+
+	mu  sync.Mutex
+	seq int
+}
+
+func NewRecordingExecutor(inner Executor, dir string) (*RecordingExecutor, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create fixture dir: %w", err)
+	}
+	return &RecordingExecutor{inner: inner, dir: dir}, nil
+}
+
+func (e *RecordingExecutor) Start(ctx context.Context, cmd Command) (Process, error) {
+	e.mu.Lock()
+	e.seq++
+	rec := &Recording{Seq: e.seq, Command: cmd, StartedAt: time.Now()}
+	e.mu.Unlock()
+
+	proc, err := e.inner.Start(ctx, cmd)
+	if err != nil {
+		rec.StartError = err.Error()
+		rec.ExitCode = -1
+		e.save(rec)
+		return nil, err
+	}
+
+	p := &recordingProcess{ctx: ctx, inner: proc, rec: rec, save: e.save}
+	p.stdout = p.capture("stdout", proc.Stdout())
+	p.stderr = p.capture("stderr", proc.Stderr())
+	return p, nil
+}
+
+func (e *RecordingExecutor) save(rec *Recording) {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(e.dir, fmt.Sprintf("%04d.json", rec.Seq)), data, 0o644)
+}
+
+type recordingProcess struct {
+	ctx    context.Context
+	inner  Process
+	rec    *Recording
+	save   func(*Recording)
+	stdout *io.PipeReader
+	stderr *io.PipeReader
+
+	mu sync.Mutex
+	wg sync.WaitGroup
+}
+
+// capture copies src through a pipe, recording each line with its offset.
+// If the consumer stops reading, capture keeps draining src so the command
+// can still exit.
+func (p *recordingProcess) capture(stream string, src io.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+	p.wg.Add(1)
+
+	go func() {
+		defer p.wg.Done()
+		defer pw.Close()
+
+		reader := bufio.NewReader(src)
+		writable := true
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				p.mu.Lock()
+				p.rec.Output = append(p.rec.Output, RecordedLine{
+					Stream:  stream,
+					Content: strings.TrimSuffix(line, "\n"),
+					Offset:  time.Since(p.rec.StartedAt),
+				})
+				p.mu.Unlock()
+
+				if writable {
+					if _, werr := io.WriteString(pw, line); werr != nil {
+						writable = false
+					}
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return pr
+}
+
+func (p *recordingProcess) Stdout() io.Reader          { return p.stdout }
+func (p *recordingProcess) Stderr() io.Reader          { return p.stderr }
+func (p *recordingProcess) Signal(sig os.Signal) error { return p.inner.Signal(sig) }
+
+func (p *recordingProcess) Wait() error {
+	// Unblock capture goroutines whose consumer gave up early.
+	p.stdout.Close()
+	p.stderr.Close()
+
+	err := p.inner.Wait()
+	p.wg.Wait()
+
+	p.rec.Duration = time.Since(p.rec.StartedAt)
+	p.rec.ExitCode = ExitCode(err)
+	if err != nil {
+		p.rec.Error = err.Error()
+	}
+	p.rec.Canceled = p.ctx.Err() != nil
+	p.save(p.rec)
+
+	return err
+}
+
+// ReplayExecutor serves invocations from a fixture directory written by
+// RecordingExecutor. Identical command lines are replayed in recorded order,
+// and output is emitted with its original timing.
+type ReplayExecutor struct {
+	mu     sync.Mutex
+	queues map[string][]*Recording
+}
+
+func NewReplayExecutor(dir string) (*ReplayExecutor, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no recordings found in %s", dir)
+	}
+
+	var recs []*Recording
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rec := &Recording{}
+		if err := json.Unmarshal(data, rec); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+		}
+		recs = append(recs, rec)
+	}
+
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Seq < recs[j].Seq })
+
+	e := &ReplayExecutor{queues: make(map[string][]*Recording)}
+	for _, rec := range recs {
+		key := rec.Command.String()
+		e.queues[key] = append(e.queues[key], rec)
+	}
+	return e, nil
+}
+
+func (e *ReplayExecutor) Start(ctx context.Context, cmd Command) (Process, error) {
+	e.mu.Lock()
+	key := cmd.String()
+	queue := e.queues[key]
+	if len(queue) == 0 {
+		e.mu.Unlock()
+		return nil, fmt.Errorf("replay: no recorded invocation for %q", key)
+	}
+	rec := queue[0]
+	e.queues[key] = queue[1:]
+	e.mu.Unlock()
+
+	if rec.StartError != "" {
+		return nil, errors.New(rec.StartError)
+	}
+
+	p := &replayProcess{ctx: ctx, rec: rec, done: make(chan struct{})}
+	var stdoutW, stderrW *io.PipeWriter
+	p.stdout, stdoutW = io.Pipe()
+	p.stderr, stderrW = io.Pipe()
+
+	go func() {
+		defer close(p.done)
+		defer stdoutW.Close()
+		defer stderrW.Close()
+
+		start := time.Now()
+		for _, line := range rec.Output {
+			if wait := line.Offset - time.Since(start); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(wait):
+				}
+			}
+
+			w := stdoutW
+			if line.Stream == "stderr" {
+				w = stderrW
+			}
+			if _, err := io.WriteString(w, line.Content+"\n"); err != nil {
+				return
+			}
+		}
+	}()
+
+	return p, nil
+}
+
+type replayProcess struct {
+	ctx    context.Context
+	rec    *Recording
+	stdout *io.PipeReader
+	stderr *io.PipeReader
+	done   chan struct{}
+}
+
+func (p *replayProcess) Stdout() io.Reader          { return p.stdout }
+func (p *replayProcess) Stderr() io.Reader          { return p.stderr }
+func (p *replayProcess) Signal(sig os.Signal) error { return nil }
+
+func (p *replayProcess) Wait() error {
+	p.stdout.Close()
+	p.stderr.Close()
+	<-p.done
+
+	// A recording that ended because swiftctl canceled it (log streams, watch
+	// mode) stays open until the replaying session cancels too.
+	if p.rec.Canceled {
+		<-p.ctx.Done()
+	}
+
+	switch {
+	case p.rec.ExitCode > 0:
+		return &ExitError{Code: p.rec.ExitCode}
+	case p.rec.Error != "":
+		return errors.New(p.rec.Error)
+	}
+	return nil
+}
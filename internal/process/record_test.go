@@ -0,0 +1,63 @@
+package process
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	fake := NewFakeExecutor().
+		On("xcrun simctl list -j", FakeResponse{Stdout: "first\n"}).
+		On("xcrun simctl list -j", FakeResponse{Stdout: "second\n"}).
+		On("xcrun simctl boot A", FakeResponse{Stderr: "already booted\n", ExitCode: 149})
+
+	rec, err := NewRecordingExecutor(fake, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewRunner(WithExecutor(rec))
+	r.RunSilent(ctx, "xcrun", []string{"simctl", "list", "-j"})
+	r.RunSilent(ctx, "xcrun", []string{"simctl", "boot", "A"})
+	r.RunSilent(ctx, "xcrun", []string{"simctl", "list", "-j"})
+
+	replay, err := NewReplayExecutor(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = NewRunner(WithExecutor(replay))
+
+	// Identical command lines come back in recorded order, independently of
+	// other commands in between.
+	tests := []struct {
+		args []string
+		want string
+		err  string
+	}{
+		{[]string{"simctl", "boot", "A"}, "", "exit status 149: already booted\n"},
+		{[]string{"simctl", "list", "-j"}, "first\n", ""},
+		{[]string{"simctl", "list", "-j"}, "second\n", ""},
+	}
+	for _, tt := range tests {
+		out, err := r.RunSilent(ctx, "xcrun", tt.args)
+		if got := errString(err); got != tt.err {
+			t.Errorf("%v: error %q, want %q", tt.args, got, tt.err)
+		}
+		if string(out) != tt.want {
+			t.Errorf("%v: stdout %q, want %q", tt.args, out, tt.want)
+		}
+	}
+
+	if _, err := r.RunSilent(ctx, "xcrun", []string{"simctl", "list", "-j"}); err == nil {
+		t.Error("replay served more invocations than were recorded")
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -6,10 +6,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 type OutputLine struct {
@@ -17,34 +19,132 @@ type OutputLine struct {
 	Content string
 }
 
-var globalVerbose bool
+var (
+	globalVerbose  bool
+	globalExecutor Executor = ExecExecutor{}
+	globalPlan     *Plan
+)
 
 // SetGlobalVerbose sets verbose mode for all runners.
 func SetGlobalVerbose(v bool) {
 	globalVerbose = v
 }
 
+// SetGlobalExecutor sets the executor used by runners created without WithExecutor.
+func SetGlobalExecutor(e Executor) {
+	globalExecutor = e
+}
+
+// SetGlobalPlan puts all runners in dry-run mode, collecting commands into p.
+func SetGlobalPlan(p *Plan) {
+	globalPlan = p
+}
+
 type Runner struct {
-	verbose bool
+	verbose  bool
+	executor Executor
+	plan     *Plan
+}
+
+// Option configures a Runner. Constructors that build on Runner accept
+// options and pass them through.
+type Option func(*Runner)
+
+// WithExecutor routes the runner's commands through e instead of os/exec.
+func WithExecutor(e Executor) Option {
+	return func(r *Runner) {
+		r.executor = e
+	}
+}
+
+// WithPlan puts the runner in dry-run mode: queries still execute, every
+// other command is added to p and reported as a silent success.
+func WithPlan(p *Plan) Option {
+	return func(r *Runner) {
+		r.plan = p
+	}
 }
 
-func NewRunner() *Runner {
-	return &Runner{verbose: globalVerbose}
+func NewRunner(opts ...Option) *Runner {
+	r := &Runner{verbose: globalVerbose, executor: globalExecutor, plan: globalPlan}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 func (r *Runner) SetVerbose(v bool) {
 	r.verbose = v
 }
 
-func (r *Runner) logCommand(name string, args []string) {
+// DryRun reports whether mutating commands are being planned instead of run.
+func (r *Runner) DryRun() bool {
+	return r.plan != nil
+}
+
+func (r *Runner) logCommand(cmd Command) {
 	if r.verbose {
-		fmt.Fprintf(os.Stderr, "  $ %s %s\n", name, strings.Join(args, " "))
+		parts := append(append([]string{}, cmd.Env...), cmd.String())
+		fmt.Fprintf(os.Stderr, "  $ %s\n", strings.Join(parts, " "))
+	}
+}
+
+func (r *Runner) start(ctx context.Context, cmd Command) (Process, error) {
+	if r.plan != nil && !IsQuery(cmd) {
+		r.plan.Add(cmd)
+		return &fakeProcess{stdout: strings.NewReader(""), stderr: strings.NewReader("")}, nil
+	}
+	return r.executor.Start(ctx, cmd)
+}
+
+// Start launches cmd and returns the running process, for callers that need
+// to signal it. During a dry run cmd is planned and an already finished
+// process is returned.
+func (r *Runner) Start(ctx context.Context, cmd Command) (Process, error) {
+	r.logCommand(cmd)
+	return r.start(ctx, cmd)
+}
+
+// foreground counts interactive commands attached to the terminal.
+var foreground atomic.Int32
+
+// InForeground reports whether an interactive command owns the terminal.
+// Ctrl+C is then meant for it (lldb uses it to interrupt the app) rather
+// than for swiftctl.
+func InForeground() bool {
+	return foreground.Load() > 0
+}
+
+// Interactive runs cmd in the foreground with the terminal's stdin, stdout
+// and stderr, for tools like lldb. It bypasses the Executor, since fakes
+// and recordings can't stand in for a terminal session. During a dry run
+// cmd is only planned.
+func (r *Runner) Interactive(ctx context.Context, cmd Command) error {
+	r.logCommand(cmd)
+	if r.plan != nil {
+		r.plan.Add(cmd)
+		return nil
+	}
+
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	if len(cmd.Env) > 0 {
+		c.Env = append(os.Environ(), cmd.Env...)
 	}
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	foreground.Add(1)
+	defer foreground.Add(-1)
+	return c.Run()
 }
 
 // Run executes a command with streaming output via channels.
 func (r *Runner) Run(ctx context.Context, name string, args []string) (<-chan OutputLine, <-chan error) {
-	r.logCommand(name, args)
+	return r.StreamCommand(ctx, Command{Name: name, Args: args})
+}
+
+// StreamCommand is Run for a full Command, e.g. one with extra environment.
+func (r *Runner) StreamCommand(ctx context.Context, cmd Command) (<-chan OutputLine, <-chan error) {
+	r.logCommand(cmd)
 
 	outChan := make(chan OutputLine, 100)
 	errChan := make(chan error, 1)
@@ -53,21 +153,8 @@ func (r *Runner) Run(ctx context.Context, name string, args []string) (<-chan Ou
 		defer close(outChan)
 		defer close(errChan)
 
-		cmd := exec.CommandContext(ctx, name, args...)
-
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			errChan <- fmt.Errorf("stdout pipe: %w", err)
-			return
-		}
-
-		stderr, err := cmd.StderrPipe()
+		proc, err := r.start(ctx, cmd)
 		if err != nil {
-			errChan <- fmt.Errorf("stderr pipe: %w", err)
-			return
-		}
-
-		if err := cmd.Start(); err != nil {
 			errChan <- fmt.Errorf("start: %w", err)
 			return
 		}
@@ -77,7 +164,7 @@ func (r *Runner) Run(ctx context.Context, name string, args []string) (<-chan Ou
 
 		go func() {
 			defer wg.Done()
-			scanner := bufio.NewScanner(stdout)
+			scanner := bufio.NewScanner(proc.Stdout())
 			for scanner.Scan() {
 				select {
 				case <-ctx.Done():
@@ -89,7 +176,7 @@ func (r *Runner) Run(ctx context.Context, name string, args []string) (<-chan Ou
 
 		go func() {
 			defer wg.Done()
-			scanner := bufio.NewScanner(stderr)
+			scanner := bufio.NewScanner(proc.Stderr())
 			for scanner.Scan() {
 				select {
 				case <-ctx.Done():
@@ -101,7 +188,7 @@ func (r *Runner) Run(ctx context.Context, name string, args []string) (<-chan Ou
 
 		wg.Wait()
 
-		if err := cmd.Wait(); err != nil {
+		if err := proc.Wait(); err != nil {
 			errChan <- err
 			return
 		}
@@ -112,15 +199,32 @@ func (r *Runner) Run(ctx context.Context, name string, args []string) (<-chan Ou
 
 // RunSilent executes a command and returns stdout. Stderr is included in errors.
 func (r *Runner) RunSilent(ctx context.Context, name string, args []string) ([]byte, error) {
-	r.logCommand(name, args)
+	return r.RunCommand(ctx, Command{Name: name, Args: args})
+}
+
+// RunCommand is RunSilent for a full Command, e.g. one with extra environment.
+func (r *Runner) RunCommand(ctx context.Context, cmd Command) ([]byte, error) {
+	r.logCommand(cmd)
 
-	cmd := exec.CommandContext(ctx, name, args...)
+	proc, err := r.start(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
 
 	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		io.Copy(&stdout, proc.Stdout())
+	}()
+	go func() {
+		defer wg.Done()
+		io.Copy(&stderr, proc.Stderr())
+	}()
+	wg.Wait()
 
-	if err := cmd.Run(); err != nil {
+	if err := proc.Wait(); err != nil {
 		if stderr.Len() > 0 {
 			return nil, fmt.Errorf("%w: %s", err, stderr.String())
 		}
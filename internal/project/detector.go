@@ -16,9 +16,9 @@ type Detector struct {
 	runner *process.Runner
 }
 
-func NewDetector() *Detector {
+func NewDetector(opts ...process.Option) *Detector {
 	return &Detector{
-		runner: process.NewRunner(),
+		runner: process.NewRunner(opts...),
 	}
 }
 
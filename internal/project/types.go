@@ -1,6 +1,10 @@
 package project
 
-import "github.com/arnavsurve/swiftctl/internal/device"
+import (
+	"path/filepath"
+
+	"github.com/arnavsurve/swiftctl/internal/device"
+)
 
 type ProjectType int
 
@@ -33,6 +37,11 @@ type ProjectInfo struct {
 	Platforms []device.Platform `json:"platforms"`
 }
 
+// Root returns the directory containing the project, workspace or Package.swift.
+func (p *ProjectInfo) Root() string {
+	return filepath.Dir(p.Path)
+}
+
 type Target struct {
 	Name        string          `json:"name"`
 	Platform    device.Platform `json:"platform"`
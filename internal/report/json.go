@@ -0,0 +1,133 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+)
+
+const schemaVersion = 1
+
+type jsonReport struct {
+	SchemaVersion int        `json:"schema_version"`
+	Build         *jsonBuild `json:"build,omitempty"`
+	Tests         *jsonTests `json:"tests,omitempty"`
+}
+
+type jsonBuild struct {
+	Success         bool             `json:"success"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	ProductPath     string           `json:"product_path,omitempty"`
+	Errors          []jsonDiagnostic `json:"errors"`
+	Warnings        []jsonDiagnostic `json:"warnings"`
+}
+
+type jsonDiagnostic struct {
+	Message  string     `json:"message"`
+	File     string     `json:"file,omitempty"`
+	Line     int        `json:"line,omitempty"`
+	Column   int        `json:"column,omitempty"`
+	Category string     `json:"category,omitempty"`
+	Notes    []jsonNote `json:"notes,omitempty"`
+}
+
+type jsonNote struct {
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+type jsonTests struct {
+	Success         bool       `json:"success"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	Counts          jsonCounts `json:"counts"`
+	Cases           []jsonCase `json:"cases"`
+}
+
+type jsonCounts struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+type jsonCase struct {
+	Suite           string        `json:"suite,omitempty"`
+	Name            string        `json:"name"`
+	Status          string        `json:"status"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	Failures        []jsonFailure `json:"failures,omitempty"`
+}
+
+type jsonFailure struct {
+	Message string `json:"message"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// WriteJSON writes the report in the schema documented on the package.
+func WriteJSON(w io.Writer, r Report) error {
+	out := jsonReport{SchemaVersion: schemaVersion}
+
+	if r.Build != nil {
+		out.Build = &jsonBuild{
+			Success:         r.Build.Success,
+			DurationSeconds: r.Build.Duration.Seconds(),
+			ProductPath:     r.Build.ProductPath,
+			Errors:          jsonDiagnostics(r.Build.Errors),
+			Warnings:        jsonDiagnostics(r.Build.Warnings),
+		}
+	}
+
+	if r.Tests != nil {
+		passed, failed, skipped := r.Tests.Counts()
+		out.Tests = &jsonTests{
+			Success:         r.Tests.Success,
+			DurationSeconds: r.Tests.Duration.Seconds(),
+			Counts: jsonCounts{
+				Total:   len(r.Tests.Cases),
+				Passed:  passed,
+				Failed:  failed,
+				Skipped: skipped,
+			},
+			Cases: []jsonCase{},
+		}
+		for _, c := range r.Tests.Cases {
+			jc := jsonCase{
+				Suite:           c.Suite,
+				Name:            c.Name,
+				Status:          string(c.Status),
+				DurationSeconds: c.Duration.Seconds(),
+			}
+			for _, f := range c.Failures {
+				jc.Failures = append(jc.Failures, jsonFailure{Message: f.Message, File: f.File, Line: f.Line})
+			}
+			out.Tests.Cases = append(out.Tests.Cases, jc)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(out)
+}
+
+func jsonDiagnostics(events []build.Event) []jsonDiagnostic {
+	out := make([]jsonDiagnostic, 0, len(events))
+	for _, e := range events {
+		d := jsonDiagnostic{
+			Message:  e.Message,
+			File:     e.File,
+			Line:     e.Line,
+			Column:   e.Column,
+			Category: e.Category,
+		}
+		for _, n := range e.Notes {
+			d.Notes = append(d.Notes, jsonNote{Message: n.Message, File: n.File, Line: n.Line, Column: n.Column})
+		}
+		out = append(out, d)
+	}
+	return out
+}
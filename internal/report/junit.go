@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Cases     []junitCase `xml:"testcase"`
+	SystemOut string      `xml:"system-out,omitempty"`
+}
+
+type junitCase struct {
+	Classname string         `xml:"classname,attr"`
+	Name      string         `xml:"name,attr"`
+	Time      string         `xml:"time,attr"`
+	File      string         `xml:"file,attr,omitempty"`
+	Line      int            `xml:"line,attr,omitempty"`
+	Failures  []junitFailure `xml:"failure"`
+	Skipped   *struct{}      `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML. Each test becomes a testcase in
+// a suite named after its class; build errors become failing testcases in a
+// "build" suite so they show up in CI test views.
+func WriteJUnit(w io.Writer, r Report) error {
+	out := junitSuites{Name: "swiftctl"}
+	var total time.Duration
+
+	if r.Build != nil {
+		out.Suites = append(out.Suites, junitBuildSuite(r.Build))
+		total = r.Build.Duration
+	}
+
+	if r.Tests != nil {
+		out.Suites = append(out.Suites, junitTestSuites(r.Tests)...)
+		total = r.Tests.Duration // includes building the tests
+	}
+
+	for _, s := range out.Suites {
+		out.Tests += s.Tests
+		out.Failures += s.Failures
+		out.Skipped += s.Skipped
+	}
+	out.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitBuildSuite(res *build.Result) junitSuite {
+	suite := junitSuite{Name: "build", Time: seconds(res.Duration)}
+
+	for _, e := range res.Errors {
+		suite.Cases = append(suite.Cases, junitCase{
+			Classname: "build",
+			Name:      diagnosticName(e),
+			Time:      seconds(0),
+			File:      e.File,
+			Line:      e.Line,
+			Failures: []junitFailure{{
+				Message: e.Message,
+				Type:    e.Category,
+				Body:    diagnosticBody(e),
+			}},
+		})
+	}
+
+	if len(suite.Cases) == 0 {
+		c := junitCase{Classname: "build", Name: "build", Time: seconds(res.Duration)}
+		if !res.Success {
+			c.Failures = []junitFailure{{Message: "build failed"}}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	var out strings.Builder
+	for _, e := range res.Warnings {
+		fmt.Fprintf(&out, "warning: %s\n", diagnosticBody(e))
+	}
+	suite.SystemOut = out.String()
+
+	suite.Tests = len(suite.Cases)
+	for _, c := range suite.Cases {
+		if len(c.Failures) > 0 {
+			suite.Failures++
+		}
+	}
+	return suite
+}
+
+func junitTestSuites(res *build.TestResult) []junitSuite {
+	var suites []junitSuite
+	var durations []time.Duration
+	index := make(map[string]int)
+
+	for _, c := range res.Cases {
+		name := c.Suite
+		if name == "" {
+			name = "tests"
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(suites)
+			index[name] = i
+			suites = append(suites, junitSuite{Name: name})
+			durations = append(durations, 0)
+		}
+		suite := &suites[i]
+		durations[i] += c.Duration
+
+		jc := junitCase{Classname: name, Name: c.Name, Time: seconds(c.Duration)}
+		switch c.Status {
+		case build.StatusFailed:
+			suite.Failures++
+			for _, f := range c.Failures {
+				jc.Failures = append(jc.Failures, junitFailure{
+					Message: f.Message,
+					Body:    fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Message),
+				})
+			}
+			if len(jc.Failures) == 0 {
+				jc.Failures = []junitFailure{{Message: "test failed"}}
+			} else {
+				jc.File, jc.Line = c.Failures[0].File, c.Failures[0].Line
+			}
+		case build.StatusSkipped:
+			suite.Skipped++
+			jc.Skipped = &struct{}{}
+		}
+
+		suite.Cases = append(suite.Cases, jc)
+		suite.Tests++
+	}
+
+	for i := range suites {
+		suites[i].Time = seconds(durations[i])
+	}
+
+	return suites
+}
+
+func diagnosticName(e build.Event) string {
+	if e.File == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s:%d:%d", filepath.Base(e.File), e.Line, e.Column)
+}
+
+func diagnosticBody(e build.Event) string {
+	var b strings.Builder
+	if e.File != "" {
+		fmt.Fprintf(&b, "%s:%d:%d: ", e.File, e.Line, e.Column)
+	}
+	b.WriteString(e.Message)
+	for _, n := range e.Notes {
+		fmt.Fprintf(&b, "\nnote: %s", n.Message)
+	}
+	return b.String()
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,104 @@
+// Package report serializes build and test results into machine-readable
+// formats for CI systems.
+//
+// The JSON format (schema_version 1) is:
+//
+//	{
+//	  "schema_version": 1,
+//	  "build": {
+//	    "success": true,
+//	    "duration_seconds": 12.3,
+//	    "product_path": "/path/to/App.app",
+//	    "errors":   [Diagnostic],
+//	    "warnings": [Diagnostic]
+//	  },
+//	  "tests": {
+//	    "success": false,
+//	    "duration_seconds": 4.2,
+//	    "counts": {"total": 3, "passed": 1, "failed": 1, "skipped": 1},
+//	    "cases": [{
+//	      "suite": "AppTests.LoginTests",
+//	      "name": "testLogin",
+//	      "status": "passed" | "failed" | "skipped",
+//	      "duration_seconds": 0.01,
+//	      "failures": [{"message": "...", "file": "...", "line": 12}]
+//	    }]
+//	  }
+//	}
+//
+// where Diagnostic is {"message", "file", "line", "column", "category",
+// "notes": [{"message", "file", "line", "column"}]}. Either section is
+// omitted when the command didn't produce it.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+)
+
+// Report is the input to every writer. Build and Tests may be nil.
+type Report struct {
+	Build *build.Result
+	Tests *build.TestResult
+	Root  string // project root; formats that reference files make paths relative to it
+}
+
+// Writer serializes a report in one format.
+type Writer func(w io.Writer, r Report) error
+
+var writers = map[string]Writer{
+	"junit": WriteJUnit,
+	"json":  WriteJSON,
+	"sarif": WriteSARIF,
+}
+
+// Spec is a parsed --report value such as "junit=out/report.xml".
+type Spec struct {
+	Format string
+	Path   string
+}
+
+// ParseSpec parses a format=path report specification.
+func ParseSpec(s string) (Spec, error) {
+	format, path, ok := strings.Cut(s, "=")
+	if !ok || path == "" {
+		return Spec{}, fmt.Errorf("invalid report %q (expected format=path)", s)
+	}
+	if _, ok := writers[format]; !ok {
+		return Spec{}, fmt.Errorf("unknown report format %q (valid: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return Spec{Format: format, Path: path}, nil
+}
+
+// Formats lists the supported report formats.
+func Formats() []string {
+	return []string{"junit", "json", "sarif"}
+}
+
+// WriteFile writes r to spec.Path in spec.Format, creating parent directories.
+func WriteFile(spec Spec, r Report) error {
+	write, ok := writers[spec.Format]
+	if !ok {
+		return fmt.Errorf("unknown report format %q", spec.Format)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(spec.Path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.Create(spec.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s report: %w", spec.Format, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites it with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := "testdata/" + name
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs (rerun with -update to accept):\n%s", path, got)
+	}
+}
+
+// failedBuild is a build with a compiler error, a link error and a warning.
+func failedBuild() Report {
+	return Report{
+		Root: "/Users/dev/MyApp",
+		Build: &build.Result{
+			Duration: 12340 * time.Millisecond,
+			Errors: []build.Event{
+				{
+					Type:     build.EventError,
+					Message:  "cannot find 'fetchItems' in scope",
+					File:     "/Users/dev/MyApp/Sources/ContentView.swift",
+					Line:     42,
+					Column:   18,
+					Category: "Swift Compiler Error",
+					Notes:    []build.Note{{Message: "did you mean 'fetchItem'?", File: "/Users/dev/MyApp/Sources/Store.swift", Line: 7, Column: 10}},
+				},
+				{
+					Type:     build.EventError,
+					Message:  "Undefined symbol: _OBJC_CLASS_$_Analytics",
+					Category: "Link Error",
+				},
+			},
+			Warnings: []build.Event{
+				{
+					Type:    build.EventWarning,
+					Message: "initialization of immutable value 'count' was never used",
+					File:    "/Users/dev/MyApp/Sources/Model.swift",
+					Line:    12,
+					Column:  9,
+				},
+			},
+		},
+	}
+}
+
+// testRun is a test run with a pass, two failures and a skip.
+func testRun() Report {
+	tests := &build.TestResult{
+		Duration: 30 * time.Second,
+		Build: &build.Result{
+			Success:  true,
+			Duration: 25500 * time.Millisecond,
+		},
+		Cases: []build.TestCase{
+			{Suite: "MyAppTests.LoginTests", Name: "testLogin", Status: build.StatusPassed, Duration: 12 * time.Millisecond},
+			{
+				Suite:    "MyAppTests.LoginTests",
+				Name:     "testLogout",
+				Status:   build.StatusFailed,
+				Duration: 250 * time.Millisecond,
+				Failures: []build.TestFailure{
+					{Message: `XCTAssertEqual failed: ("1") is not equal to ("2")`, File: "/Users/dev/MyApp/Tests/LoginTests.swift", Line: 31},
+					{Message: "XCTAssertTrue failed <session & token>", File: "/Users/dev/MyApp/Tests/LoginTests.swift", Line: 32},
+				},
+			},
+			{Suite: "MyAppTests.StoreTests", Name: "testSync", Status: build.StatusSkipped},
+			// Swift Testing reports neither a suite nor a failure location.
+			{Name: "parsesEmptyInput()", Status: build.StatusFailed, Duration: 3 * time.Millisecond},
+		},
+	}
+	return Report{Root: "/Users/dev/MyApp", Build: tests.Build, Tests: tests}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	for name, r := range map[string]Report{"build_errors": failedBuild(), "test_run": testRun()} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJUnit(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+".junit.xml", buf.Bytes())
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	for name, r := range map[string]Report{"build_errors": failedBuild(), "test_run": testRun()} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteJSON(&buf, r); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+".json", buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifRootID  = "SRCROOT"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                  `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactID `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult              `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifArtifactID struct {
+	URI string `json:"uri"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes build errors and warnings as a SARIF 2.1.0 log. Paths
+// under r.Root are emitted relative to it so code scanning can map them
+// onto repository files.
+func WriteSARIF(w io.Writer, r Report) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "swiftctl",
+			InformationURI: "https://github.com/arnavsurve/swiftctl",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	if r.Root != "" {
+		root := (&url.URL{Scheme: "file", Path: filepath.ToSlash(r.Root) + "/"}).String()
+		run.OriginalURIBaseIDs = map[string]sarifArtifactID{sarifRootID: {URI: root}}
+	}
+
+	rules := make(map[string]bool)
+	add := func(e build.Event, level string) {
+		ruleID := sarifRuleID(e.Category, level)
+		if !rules[ruleID] {
+			rules[ruleID] = true
+			desc := e.Category
+			if desc == "" {
+				desc = "Compiler " + level
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: ruleID, ShortDescription: sarifMessage{Text: desc}})
+		}
+
+		res := sarifResult{
+			RuleID:  ruleID,
+			Level:   level,
+			Message: sarifMessage{Text: e.Message},
+		}
+		if e.File != "" {
+			res.Locations = []sarifLocation{{PhysicalLocation: sarifPhysical(r.Root, e.File, e.Line, e.Column)}}
+		}
+		for _, n := range e.Notes {
+			if n.File == "" {
+				continue
+			}
+			res.RelatedLocations = append(res.RelatedLocations, sarifLocation{
+				PhysicalLocation: sarifPhysical(r.Root, n.File, n.Line, n.Column),
+				Message:          &sarifMessage{Text: n.Message},
+			})
+		}
+		run.Results = append(run.Results, res)
+	}
+
+	if r.Build != nil {
+		for _, e := range r.Build.Errors {
+			add(e, "error")
+		}
+		for _, e := range r.Build.Warnings {
+			add(e, "warning")
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+func sarifPhysical(root, file string, line, column int) sarifPhysicalLocation {
+	loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifact(root, file)}
+	if line > 0 {
+		loc.Region = &sarifRegion{StartLine: line, StartColumn: column}
+	}
+	return loc
+}
+
+func sarifArtifact(root, file string) sarifArtifactLocation {
+	if root != "" && filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return sarifArtifactLocation{URI: relativeURI(rel), URIBaseID: sarifRootID}
+		}
+	}
+	if filepath.IsAbs(file) {
+		return sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()}
+	}
+	loc := sarifArtifactLocation{URI: relativeURI(file)}
+	// SRCROOT is only declared when there is a root.
+	if root != "" {
+		loc.URIBaseID = sarifRootID
+	}
+	return loc
+}
+
+// relativeURI percent-encodes a relative path as a URI reference.
+func relativeURI(path string) string {
+	return (&url.URL{Path: filepath.ToSlash(path)}).String()
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// sarifRuleID derives a stable rule ID such as "swift-compiler-error".
+func sarifRuleID(category, level string) string {
+	if category == "" {
+		return "compiler-" + level
+	}
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(category), "-"), "-")
+}
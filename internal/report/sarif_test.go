@@ -0,0 +1,42 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	r := Report{
+		Root: "/Users/dev/My App",
+		Build: &build.Result{
+			Errors: []build.Event{
+				{
+					Message:  "cannot find 'progress' in scope",
+					File:     "/Users/dev/My App/Sources/100% Done.swift",
+					Line:     42,
+					Column:   18,
+					Category: "Swift Compiler Error",
+					Notes: []build.Note{
+						// Outside the root, so it keeps an absolute file URI.
+						{Message: "'progress' declared here", File: "/Users/dev/Shared Kit/Progress.swift", Line: 7, Column: 5},
+						{Message: "no location"},
+					},
+				},
+				{Message: "Undefined symbol: _OBJC_CLASS_$_Analytics", Category: "Link Error"},
+			},
+			Warnings: []build.Event{
+				// Shares a prefix with the root but isn't under it.
+				{Message: "variable 'x' was never used", File: "/Users/dev/My App2/Main.swift", Line: 3, Column: 9},
+				{Message: "unreachable code", File: "Sources/Generated/Strings.swift"},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "build.sarif", buf.Bytes())
+}
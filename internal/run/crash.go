@@ -0,0 +1,130 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/crash"
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+)
+
+const (
+	// pollInterval is how often a launched app's PID is checked.
+	pollInterval = time.Second
+
+	// crashReportWait is how long to look for the .ips report after the
+	// app is gone; ReportCrash writes it a moment after the process dies.
+	crashReportWait = 5 * time.Second
+
+	// minUptime keeps watch mode from relaunching an app that crashes
+	// right after launch over and over.
+	minUptime = 5 * time.Second
+
+	maxCrashFrames = 10
+)
+
+// errCrashed is returned once a crash has been reported.
+var errCrashed = errors.New("app crashed")
+
+// awaitExit polls the app launched as pid on dev until it exits, then looks
+// for a crash report written since launch. exited is false when ctx ended
+// first.
+func (r *Runner) awaitExit(ctx context.Context, dev *device.Device, pid int, launched time.Time, bundleID string) (exited bool, rep *crash.Report) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticker.C:
+		}
+
+		running, err := r.deviceManager.Running(ctx, dev, pid)
+		if err != nil {
+			// Can't tell; stop watching rather than report a false crash.
+			return false, nil
+		}
+		if !running {
+			break
+		}
+	}
+
+	deadline := time.Now().Add(crashReportWait)
+	for {
+		rep, err := crash.Find(crash.Dirs(dev.UDID), bundleID, "", launched)
+		if err == nil && rep != nil {
+			return true, rep
+		}
+		if time.Now().After(deadline) {
+			return true, nil
+		}
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// reportAppExit prints how t's app ended: a crash summary when there is a
+// report, or a plain exit notice.
+func (r *Runner) reportAppExit(ctx context.Context, t *target, rep *crash.Report) {
+	if rep == nil {
+		t.out.Warning("App exited (PID %d)", t.pid)
+		t.log.Mark("App exited (PID %d)", t.pid)
+		return
+	}
+
+	if t.appPath != "" {
+		r.symbolicate(ctx, t.out, rep, filepath.Dir(t.appPath))
+	}
+	PrintCrash(t.out, rep, maxCrashFrames)
+	t.log.Mark("Crashed: %s (%s)", rep.Title(), rep.Path)
+}
+
+// symbolicate resolves rep's frames with the dSYMs and binaries in the
+// build products dir. Only binaries whose UUID matches an image in the
+// report are used, so a crash from an older build is left as is.
+func (r *Runner) symbolicate(ctx context.Context, out *ui.Renderer, rep *crash.Report, productsDir string) {
+	index := crash.Index(crash.Binaries(productsDir))
+	if len(index) == 0 {
+		return
+	}
+	if _, err := crash.NewSymbolicator(r.procOpts...).Symbolicate(ctx, rep, index); err != nil {
+		out.Dim("Symbolication failed: %v", err)
+	}
+}
+
+// PrintCrash prints a short summary of rep: the exception, any fatal error
+// message and the top maxFrames of the crashed thread's backtrace (all of
+// it when maxFrames is 0).
+func PrintCrash(out *ui.Renderer, rep *crash.Report, maxFrames int) {
+	out.Error("%s crashed: %s", rep.Process, rep.Title())
+	for _, msg := range rep.Messages {
+		out.Info("%s", strings.TrimSpace(msg))
+	}
+
+	thread := fmt.Sprintf("Thread %d", rep.FaultingThread)
+	if rep.ThreadName != "" {
+		thread += " (" + rep.ThreadName + ")"
+	}
+	out.Info("%s crashed:", thread)
+
+	frames := rep.Frames
+	if maxFrames > 0 && len(frames) > maxFrames {
+		frames = frames[:maxFrames]
+	}
+	for i, f := range frames {
+		out.Info("  %-3d %s", i, f)
+	}
+	if n := len(rep.Frames) - len(frames); n > 0 {
+		out.Dim("  ... %d more frames", n)
+	}
+	out.Dim("Report: %s", rep.Path)
+}
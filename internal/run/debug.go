@@ -0,0 +1,208 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/watcher"
+)
+
+// Debug builds, installs and launches the app suspended, then runs lldb
+// attached to it in the foreground. With cfg.Watch, quitting lldb goes back
+// to watching, and the next change starts a new session on a fresh build.
+func (r *Runner) Debug(ctx context.Context, cfg Config) error {
+	dev, err := r.debugDevice(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// lldb owns the terminal, so the app can't have it too.
+	cfg.Launch.ConsolePTY = false
+	cfg.Launch.WaitForDebugger = true
+	if err := cfg.Launch.Validate(dev); err != nil {
+		return err
+	}
+	t := r.targets([]*device.Device{dev})[0]
+
+	if !cfg.Watch || r.procRunner.DryRun() {
+		return r.debugSession(ctx, cfg, t)
+	}
+
+	w, err := watcher.New(750 * time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("watcher failed: %w", err)
+	}
+	defer w.Close()
+
+	if len(cfg.WatchPatterns) > 0 {
+		w.SetPatterns(cfg.WatchPatterns)
+	}
+
+	if err := w.AddRecursive("."); err != nil {
+		return fmt.Errorf("watch directory failed: %w", err)
+	}
+
+	changes := w.Watch(ctx)
+
+	for {
+		if err := r.debugSession(ctx, cfg, t); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			r.renderer.Error("%v", err)
+		}
+
+		r.renderer.Dim("Watching for changes to start a new debug session (Ctrl+C to stop)...")
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			r.renderer.Info("Changed: %s", filepath.Base(change.Path))
+		}
+
+		// Drain any queued events (from atomic saves generating multiple events)
+		drainDone := time.After(100 * time.Millisecond)
+	drain:
+		for {
+			select {
+			case <-changes:
+			case <-drainDone:
+				break drain
+			}
+		}
+	}
+}
+
+// Attach runs lldb attached to the already running app with bundleID.
+func (r *Runner) Attach(ctx context.Context, cfg Config, bundleID string) error {
+	dev, err := r.debugDevice(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	pid, err := r.deviceManager.AppPID(ctx, dev, bundleID)
+	if err != nil && !r.procRunner.DryRun() {
+		return err
+	}
+
+	r.renderer.Info("Attaching to %s (PID %d)", bundleID, pid)
+	return r.lldb(ctx, cfg, dev, pid)
+}
+
+// debugDevice resolves the one device to debug on.
+func (r *Runner) debugDevice(ctx context.Context, cfg Config) (*device.Device, error) {
+	if cfg.Platform == device.PlatformMacOS || r.project.Type == project.ProjectTypeSPM {
+		return nil, fmt.Errorf("debug supports simulators and devices; run lldb directly for programs on this Mac")
+	}
+	if len(cfg.Devices) > 1 {
+		return nil, fmt.Errorf("debug one device at a time (got %d)", len(cfg.Devices))
+	}
+
+	dev, err := r.ResolveDevice(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	r.renderer.Info("Device: %s (%s)", dev.Name, dev.OSVersion)
+	return dev, nil
+}
+
+// debugSession builds and installs the app, launches it waiting for the
+// debugger and runs lldb until the user quits it.
+func (r *Runner) debugSession(ctx context.Context, cfg Config, t *target) error {
+	appPath, bundleID, err := r.buildApp(ctx, cfg, t.dev)
+	if err != nil {
+		return err
+	}
+	if err := r.install(ctx, cfg, t, appPath); err != nil {
+		return err
+	}
+
+	_ = r.deviceManager.Terminate(ctx, t.dev, bundleID)
+
+	t.out.StartSpinner("Launching...")
+	pid, err := r.deviceManager.Launch(ctx, t.dev, bundleID, cfg.Launch)
+	if err != nil {
+		t.out.StopSpinner(false)
+		return fmt.Errorf("launch failed: %w", err)
+	}
+	t.out.StopSpinner(true)
+	t.out.Success("Launched suspended (PID %d)", pid)
+
+	return r.lldb(ctx, cfg, t.dev, pid)
+}
+
+// lldb runs an interactive lldb session attached to pid on dev.
+func (r *Runner) lldb(ctx context.Context, cfg Config, dev *device.Device, pid int) error {
+	if !r.procRunner.DryRun() {
+		r.renderer.Dim("Starting lldb; set breakpoints, then 'continue' to run the app ('quit' to end)")
+	}
+
+	args := lldbArgs(dev, pid, r.sourceMap(cfg))
+	err := r.procRunner.Interactive(ctx, process.Command{Name: "xcrun", Args: args})
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("lldb: %w", err)
+	}
+	return nil
+}
+
+// sourceMap returns cfg.SourceMap as sorted pairs, with local paths made
+// absolute against the project root.
+func (r *Runner) sourceMap(cfg Config) [][2]string {
+	pairs := make([][2]string, 0, len(cfg.SourceMap))
+	for from, to := range cfg.SourceMap {
+		if !filepath.IsAbs(to) {
+			to = filepath.Join(r.project.Root(), to)
+		}
+		pairs = append(pairs, [2]string{from, to})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs
+}
+
+// lldbArgs builds `xcrun lldb` arguments that select the device's platform,
+// apply the source map and attach to pid. Physical devices are reached
+// through CoreDevice, which starts debugserver on the device.
+func lldbArgs(dev *device.Device, pid int, sourceMap [][2]string) []string {
+	args := []string{"lldb"}
+	command := func(format string, a ...any) {
+		args = append(args, "-o", fmt.Sprintf(format, a...))
+	}
+
+	command("platform select %s", lldbPlatform(dev))
+	for _, m := range sourceMap {
+		command("settings append target.source-map %q %q", m[0], m[1])
+	}
+	if dev.Type == device.DeviceTypePhysical {
+		command("device select %s", dev.UDID)
+		command("device process attach --pid %d", pid)
+	} else {
+		command("process attach --pid %d", pid)
+	}
+	return args
+}
+
+// lldbPlatform returns lldb's platform plugin name for dev.
+func lldbPlatform(dev *device.Device) string {
+	name := "ios"
+	switch dev.Platform {
+	case device.PlatformTVOS:
+		name = "tvos"
+	case device.PlatformWatchOS:
+		name = "watchos"
+	case device.PlatformVisionOS:
+		name = "xros"
+	}
+	if dev.Type == device.DeviceTypePhysical {
+		return "remote-" + name
+	}
+	return name + "-simulator"
+}
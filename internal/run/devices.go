@@ -0,0 +1,123 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/session"
+	"github.com/arnavsurve/swiftctl/internal/ui"
+)
+
+// target is a device the app is deployed to, with the renderer and log
+// prefix its output goes through. With a single device both are plain.
+type target struct {
+	dev    *device.Device
+	out    *ui.Renderer
+	prefix string
+	log    *session.Log // nil unless logs are being saved
+
+	appPath string // last deployed; its build products are searched for symbols
+
+	pid      int // of the launched app; 0 when it isn't monitored
+	launched time.Time
+}
+
+// targets wraps devs, giving each a colored name prefix when there are
+// several so their output can be told apart.
+func (r *Runner) targets(devs []*device.Device) []*target {
+	if len(devs) == 1 {
+		return []*target{{dev: devs[0], out: r.renderer}}
+	}
+
+	width := 0
+	for _, dev := range devs {
+		width = max(width, len(dev.Name))
+	}
+
+	targets := make([]*target, len(devs))
+	for i, dev := range devs {
+		prefix := ui.Prefix(dev.Name, i, width)
+		targets[i] = &target{dev: dev, out: r.renderer.WithPrefix(prefix), prefix: prefix}
+	}
+	return targets
+}
+
+// ResolveDevices resolves every device named in cfg, or picks one as
+// ResolveDevice does. Several devices must share a platform and kind
+// (simulator or physical) since they run the same build.
+func (r *Runner) ResolveDevices(ctx context.Context, cfg Config) ([]*device.Device, error) {
+	if len(cfg.Devices) <= 1 {
+		dev, err := r.ResolveDevice(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return []*device.Device{dev}, nil
+	}
+
+	var devs []*device.Device
+	seen := make(map[string]bool)
+	for _, name := range cfg.Devices {
+		dev, err := r.deviceManager.Get(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("device not found: %w", err)
+		}
+		if seen[dev.UDID] {
+			continue
+		}
+		seen[dev.UDID] = true
+		devs = append(devs, dev)
+	}
+
+	first := devs[0]
+	for _, dev := range devs[1:] {
+		if dev.Platform != first.Platform {
+			return nil, fmt.Errorf("%s is a %s device but %s is %s; run one platform at a time", dev.Name, dev.Platform, first.Name, first.Platform)
+		}
+		if dev.Type != first.Type {
+			return nil, fmt.Errorf("can't mix simulators and physical devices (%s, %s) in one run", first.Name, dev.Name)
+		}
+	}
+
+	return devs, nil
+}
+
+// deployAll deploys the app to every target concurrently. A failure on one
+// device is reported on its output without stopping the others; an error
+// is returned only when no device could be deployed to.
+func (r *Runner) deployAll(ctx context.Context, cfg Config, targets []*target, appPath, bundleID string) ([]*target, error) {
+	if len(targets) == 1 {
+		if err := r.deploy(ctx, cfg, targets[0], appPath, bundleID); err != nil {
+			return nil, err
+		}
+		return targets, nil
+	}
+
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Go(func() {
+			errs[i] = r.deploy(ctx, cfg, t, appPath, bundleID)
+		})
+	}
+	wg.Wait()
+
+	var deployed []*target
+	for i, t := range targets {
+		if errs[i] != nil {
+			t.out.Error("%v", errs[i])
+			continue
+		}
+		deployed = append(deployed, t)
+	}
+
+	if len(deployed) == 0 {
+		return nil, fmt.Errorf("deploy failed on all %d devices", len(targets))
+	}
+	if len(deployed) < len(targets) {
+		r.renderer.Warning("Running on %d of %d devices", len(deployed), len(targets))
+	}
+	return deployed, nil
+}
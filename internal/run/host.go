@@ -0,0 +1,369 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/session"
+	"github.com/arnavsurve/swiftctl/internal/watcher"
+)
+
+// stopTimeout is how long a host process gets to exit after SIGTERM before
+// it is killed.
+const stopTimeout = 3 * time.Second
+
+// hostProcess is a macOS app or SPM executable running on this Mac.
+type hostProcess struct {
+	proc   process.Process
+	exited chan struct{}
+	err    error // Wait's result, set before exited is closed
+}
+
+// runHost builds and runs the product as a local process: the executable
+// inside a macOS .app, or an SPM executable product.
+func (r *Runner) runHost(ctx context.Context, cfg Config) error {
+	if cfg.Launch.WaitForDebugger {
+		return fmt.Errorf("--wait-for-debugger is not supported when running on this Mac")
+	}
+	if cfg.Launch.ConsolePTY {
+		return fmt.Errorf("--console-pty is not needed when running on this Mac (output is always attached)")
+	}
+	if err := cfg.Launch.Validate(nil); err != nil {
+		return err
+	}
+
+	r.renderer.Info("Device: this Mac")
+
+	var log *session.Log
+	if cfg.SaveLogs && !r.procRunner.DryRun() {
+		r.session = session.New(r.project.Root())
+		defer r.session.Close()
+		var err error
+		if log, err = r.session.Open("mac", ""); err != nil {
+			return fmt.Errorf("session log: %w", err)
+		}
+		r.renderer.Dim("Saving logs to %s", r.session.Dir())
+	}
+
+	exe, err := r.buildHost(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	if r.procRunner.DryRun() {
+		// The launch is only planned, so there is nothing to follow.
+		_, err := r.startHost(exe, cfg.Launch, nil)
+		return err
+	}
+
+	if cfg.Watch {
+		return r.runHostWithWatch(ctx, cfg, exe, log)
+	}
+
+	p, err := r.startHost(exe, cfg.Launch, log)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		p.stop(stopTimeout)
+		return nil
+	case <-p.exited:
+		return r.reportExit(p)
+	}
+}
+
+// buildHost builds for macOS and returns the executable to run.
+func (r *Runner) buildHost(ctx context.Context, cfg Config) (string, error) {
+	if r.project.Type == project.ProjectTypeSPM {
+		return r.buildSPMExecutable(ctx, cfg)
+	}
+
+	scheme := r.scheme(cfg)
+	result, err := r.build(ctx, cfg, scheme, "platform=macOS")
+	if err != nil {
+		return "", err
+	}
+
+	// Build settings know the executable inside the bundle (or the tool
+	// itself for command-line targets).
+	if result.ExecutablePath != "" {
+		return result.ExecutablePath, nil
+	}
+
+	appPath := result.ProductPath
+	if appPath == "" {
+		config := string(cfg.Configuration)
+		if config == "" {
+			config = "Debug"
+		}
+		appPath, err = FindApp(cfg.DerivedData, r.project.Name, scheme, config, device.PlatformMacOS, false)
+		if err != nil {
+			if !r.procRunner.DryRun() {
+				return "", fmt.Errorf("app not found: %w", err)
+			}
+			return "<" + scheme + ".app>/Contents/MacOS/" + scheme, nil
+		}
+	}
+
+	app, err := ReadAppInfo(appPath)
+	if err != nil {
+		if !r.procRunner.DryRun() {
+			return "", fmt.Errorf("reading Info.plist failed: %w", err)
+		}
+		return filepath.Join(appPath, "Contents", "MacOS", scheme), nil
+	}
+	r.renderer.Dim("%s", app.Summary())
+
+	if app.Executable == "" {
+		return "", fmt.Errorf("%s has no CFBundleExecutable", appPath)
+	}
+	return filepath.Join(appPath, "Contents", "MacOS", app.Executable), nil
+}
+
+// buildSPMExecutable builds an executable product with swift build and
+// returns its path in the bin directory.
+func (r *Runner) buildSPMExecutable(ctx context.Context, cfg Config) (string, error) {
+	product := cfg.Scheme
+	if product == "" {
+		product = r.spmExecutable()
+	}
+	if product == "" {
+		return "", fmt.Errorf("no executable product found in %s (pass one with -s)", r.project.Name)
+	}
+
+	result, err := r.build(ctx, cfg, product, "")
+	if err != nil {
+		return "", err
+	}
+	if result.ExecutablePath != "" {
+		return result.ExecutablePath, nil
+	}
+
+	binPath, err := r.builder.BinPath(ctx, build.Config{Configuration: cfg.Configuration})
+	if err != nil {
+		return "", err
+	}
+	exe := filepath.Join(binPath, product)
+	if _, err := os.Stat(exe); err != nil && !r.procRunner.DryRun() {
+		return "", fmt.Errorf("%s is not an executable product", product)
+	}
+	return exe, nil
+}
+
+// spmExecutable returns the package's first executable target, which SPM
+// exposes as a product of the same name.
+func (r *Runner) spmExecutable() string {
+	for _, t := range r.project.Targets {
+		if t.ProductType == "executable" {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// startHost launches exe and copies its output to the terminal, or to the
+// files named in opts, and to the session log.
+func (r *Runner) startHost(exe string, opts device.LaunchOptions, log *session.Log) (*hostProcess, error) {
+	stdout, err := outputFile(opts.Stdout, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := outputFile(opts.Stderr, os.Stderr)
+	if err != nil {
+		closeOutput(stdout)
+		return nil, err
+	}
+
+	// Not tied to the caller's context: cancelling would kill the process
+	// outright, and stop gives it a chance to exit cleanly first.
+	proc, err := r.procRunner.Start(context.Background(), process.Command{Name: exe, Args: opts.Args, Env: opts.Env})
+	if err != nil {
+		closeOutput(stdout)
+		closeOutput(stderr)
+		return nil, fmt.Errorf("launch failed: %w", err)
+	}
+
+	if !r.procRunner.DryRun() {
+		r.renderer.Success("Launched %s", filepath.Base(exe))
+		log.Mark("Launched %s", exe)
+		for _, f := range []string{opts.Stdout, opts.Stderr} {
+			if f != "" {
+				r.renderer.Dim("Writing output to %s", f)
+			}
+		}
+	}
+
+	p := &hostProcess{proc: proc, exited: make(chan struct{})}
+	go func() {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = io.Copy(io.MultiWriter(stdout, log), proc.Stdout())
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = io.Copy(io.MultiWriter(stderr, log), proc.Stderr())
+		}()
+		wg.Wait()
+
+		p.err = proc.Wait()
+		closeOutput(stdout)
+		closeOutput(stderr)
+		log.Mark("Exited with status %d", process.ExitCode(p.err))
+		close(p.exited)
+	}()
+
+	return p, nil
+}
+
+// stop sends SIGTERM and waits up to grace for the process to exit before
+// killing it.
+func (p *hostProcess) stop(grace time.Duration) {
+	select {
+	case <-p.exited:
+		return
+	default:
+	}
+
+	_ = p.proc.Signal(syscall.SIGTERM)
+	select {
+	case <-p.exited:
+		return
+	case <-time.After(grace):
+	}
+
+	_ = p.proc.Signal(os.Kill)
+	<-p.exited
+}
+
+// reportExit describes how a host process ended and returns an error for a
+// non-zero status.
+func (r *Runner) reportExit(p *hostProcess) error {
+	code := process.ExitCode(p.err)
+	if code == 0 {
+		r.renderer.Dim("Process exited")
+		return nil
+	}
+	return fmt.Errorf("process exited with status %d", code)
+}
+
+func (r *Runner) runHostWithWatch(ctx context.Context, cfg Config, exe string, log *session.Log) error {
+	w, err := watcher.New(750 * time.Millisecond)
+	if err != nil {
+		return fmt.Errorf("watcher failed: %w", err)
+	}
+	defer w.Close()
+
+	if len(cfg.WatchPatterns) > 0 {
+		w.SetPatterns(cfg.WatchPatterns)
+	}
+
+	if err := w.AddRecursive("."); err != nil {
+		return fmt.Errorf("watch directory failed: %w", err)
+	}
+
+	changes := w.Watch(ctx)
+
+	current, err := r.startHost(exe, cfg.Launch, log)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if current != nil {
+			current.stop(stopTimeout)
+		}
+	}()
+
+	r.renderer.Dim("Watching for changes (Ctrl+C to stop)...")
+
+	for {
+		var exited <-chan struct{}
+		if current != nil {
+			exited = current.exited
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-exited:
+			if err := r.reportExit(current); err != nil {
+				r.renderer.Warning("%v", err)
+			}
+			current = nil
+			r.renderer.Dim("Waiting for changes to restart...")
+
+		case change, ok := <-changes:
+			if !ok {
+				return nil
+			}
+
+			r.renderer.Info("Changed: %s", filepath.Base(change.Path))
+			log.Mark("Changed %s; rebuilding", change.Path)
+
+			// Keep the old process running until the rebuild succeeds.
+			newExe, err := r.buildHost(ctx, cfg)
+			if err != nil {
+				r.renderer.Error("Rebuild failed: %v", err)
+				log.Mark("Rebuild failed; previous build still running")
+				continue
+			}
+
+			// Drain any queued events (from atomic saves generating multiple events)
+			drainDone := time.After(100 * time.Millisecond)
+		drain:
+			for {
+				select {
+				case <-changes:
+				case <-drainDone:
+					break drain
+				}
+			}
+
+			if current != nil {
+				r.renderer.StartSpinner("Stopping...")
+				current.stop(stopTimeout)
+				r.renderer.StopSpinner(true)
+			}
+
+			exe = newExe
+			current, err = r.startHost(exe, cfg.Launch, log)
+			if err != nil {
+				r.renderer.Error("%v", err)
+			}
+		}
+	}
+}
+
+// outputFile opens path for a host process's output, or returns fallback
+// when path is empty.
+func outputFile(path string, fallback io.Writer) (io.Writer, error) {
+	if path == "" {
+		return fallback, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("open output file: %w", err)
+	}
+	return f, nil
+}
+
+// closeOutput closes w if outputFile opened it.
+func closeOutput(w io.Writer) {
+	if f, ok := w.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		_ = f.Close()
+	}
+}
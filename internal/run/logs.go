@@ -2,42 +2,236 @@ package run
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/arnavsurve/swiftctl/internal/device"
 	"github.com/arnavsurve/swiftctl/internal/process"
+	"github.com/arnavsurve/swiftctl/internal/ui"
 )
 
+// LogEntry is one message from the unified log, or one line of console
+// output when the app's stdio is attached instead.
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level,omitempty"` // debug, info, default, error or fault; empty for console output
+	Subsystem string    `json:"subsystem,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Process   string    `json:"process,omitempty"`
+	PID       int       `json:"pid,omitempty"`
+	Message   string    `json:"message"`
+	Device    string    `json:"device,omitempty"` // set when streaming from several devices
+}
+
+// logLevels orders the unified log's message types by severity.
+var logLevels = map[string]int{"debug": 0, "info": 1, "default": 2, "error": 3, "fault": 4}
+
+// LogFilter selects log entries. Zero values match everything.
+type LogFilter struct {
+	Level     string // minimum level
+	Subsystem string
+	Category  string
+	Grep      *regexp.Regexp // matched against the message
+}
+
+// NewLogFilter validates level and compiles grep.
+func NewLogFilter(level, subsystem, category, grep string) (LogFilter, error) {
+	f := LogFilter{Level: strings.ToLower(level), Subsystem: subsystem, Category: category}
+	if _, ok := logLevels[f.Level]; f.Level != "" && !ok {
+		return f, fmt.Errorf("invalid log level: %s (valid: debug, info, default, error, fault)", level)
+	}
+	if grep != "" {
+		re, err := regexp.Compile(grep)
+		if err != nil {
+			return f, fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+		f.Grep = re
+	}
+	return f, nil
+}
+
+// Match reports whether e passes the filter. Console output has no level,
+// subsystem or category, so only Grep applies to it.
+func (f LogFilter) Match(e LogEntry) bool {
+	if f.Grep != nil && !f.Grep.MatchString(e.Message) {
+		return false
+	}
+	if e.Level == "" {
+		return true
+	}
+	if f.Level != "" && logLevels[e.Level] < logLevels[f.Level] {
+		return false
+	}
+	if f.Subsystem != "" && e.Subsystem != f.Subsystem {
+		return false
+	}
+	if f.Category != "" && e.Category != f.Category {
+		return false
+	}
+	return true
+}
+
+// logStreamArgs builds the `log stream` invocation for the filter. Debug
+// and info messages are only streamed when asked for, and subsystem and
+// category are pushed into the predicate, quoted, so log does the filtering.
+func logStreamArgs(bundleID string, f LogFilter) []string {
+	args := []string{"log", "stream", "--style", "ndjson"}
+	switch f.Level {
+	case "debug", "info":
+		args = append(args, "--level", f.Level)
+	}
+
+	var clauses []string
+	if bundleID != "" {
+		clauses = append(clauses, "processImagePath CONTAINS "+strconv.Quote(bundleID))
+	}
+	if f.Subsystem != "" {
+		clauses = append(clauses, "subsystem == "+strconv.Quote(f.Subsystem))
+	}
+	if f.Category != "" {
+		clauses = append(clauses, "category == "+strconv.Quote(f.Category))
+	}
+	if len(clauses) > 0 {
+		args = append(args, "--predicate", strings.Join(clauses, " AND "))
+	}
+	return args
+}
+
+// parseLogEntry decodes one line of `log stream --style ndjson`. Lines that
+// aren't log events (the "Filtering the log data" banner, activities) are
+// skipped.
+func parseLogEntry(line string) (LogEntry, bool) {
+	var raw struct {
+		Timestamp        string `json:"timestamp"`
+		EventType        string `json:"eventType"`
+		MessageType      string `json:"messageType"`
+		Subsystem        string `json:"subsystem"`
+		Category         string `json:"category"`
+		ProcessImagePath string `json:"processImagePath"`
+		ProcessID        int    `json:"processID"`
+		EventMessage     string `json:"eventMessage"`
+	}
+	if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &raw) != nil {
+		return LogEntry{}, false
+	}
+	if raw.EventType != "" && raw.EventType != "logEvent" {
+		return LogEntry{}, false
+	}
+
+	ts, err := time.Parse("2006-01-02 15:04:05.000000-0700", raw.Timestamp)
+	if err != nil {
+		ts = time.Now()
+	}
+	level := strings.ToLower(raw.MessageType)
+	if level == "" {
+		level = "default"
+	}
+
+	return LogEntry{
+		Timestamp: ts,
+		Level:     level,
+		Subsystem: raw.Subsystem,
+		Category:  raw.Category,
+		Process:   filepath.Base(raw.ProcessImagePath),
+		PID:       raw.ProcessID,
+		Message:   raw.EventMessage,
+	}, true
+}
+
+// Scope is "subsystem:category", or whichever of the two is set.
+func (e LogEntry) Scope() string {
+	switch {
+	case e.Subsystem != "" && e.Category != "":
+		return e.Subsystem + ":" + e.Category
+	case e.Subsystem != "":
+		return e.Subsystem
+	default:
+		return e.Category
+	}
+}
+
+// String formats e as a plain "level [scope] message" line, as saved in
+// session logs.
+func (e LogEntry) String() string {
+	if e.Level == "" {
+		return e.Message
+	}
+	line := ui.LevelTag(e.Level) + " "
+	if scope := e.Scope(); scope != "" {
+		line += "[" + scope + "] "
+	}
+	return line + e.Message
+}
+
+// WriteLog prints e to stdout, as a JSON line when jsonOut is set and
+// otherwise through renderer with prefix.
+func WriteLog(renderer *ui.Renderer, prefix string, e LogEntry, jsonOut bool) {
+	if jsonOut {
+		data, err := json.Marshal(e)
+		if err == nil {
+			fmt.Println(string(data))
+		}
+		return
+	}
+	renderer.Log(prefix, e.Timestamp, e.Level, e.Scope(), e.Message)
+}
+
 type LogStreamer struct {
 	runner   *process.Runner
+	manager  *device.Manager
 	device   *device.Device
 	bundleID string
+	launch   device.LaunchOptions
+	filter   LogFilter
 }
 
-func NewLogStreamer(dev *device.Device, bundleID string) *LogStreamer {
+// NewLogStreamer streams logs for bundleID on dev; an empty bundleID
+// streams every process on the device.
+func NewLogStreamer(dev *device.Device, bundleID string, opts ...process.Option) *LogStreamer {
 	return &LogStreamer{
-		runner:   process.NewRunner(),
+		runner:   process.NewRunner(opts...),
+		manager:  device.NewManager(opts...),
 		device:   dev,
 		bundleID: bundleID,
 	}
 }
 
-// Stream starts streaming logs and returns a channel of log lines.
-func (l *LogStreamer) Stream(ctx context.Context) (<-chan string, <-chan error) {
-	outChan := make(chan string, 100)
+// SetLaunch sets the launch options used when streaming the app's console
+// (physical devices, or ConsolePTY), where following it means (re)launching
+// the app.
+func (l *LogStreamer) SetLaunch(opts device.LaunchOptions) {
+	l.launch = opts
+}
+
+// SetFilter drops entries that don't match f.
+func (l *LogStreamer) SetFilter(f LogFilter) {
+	l.filter = f
+}
+
+// Stream starts streaming logs and returns a channel of log entries.
+func (l *LogStreamer) Stream(ctx context.Context) (<-chan LogEntry, <-chan error) {
+	outChan := make(chan LogEntry, 100)
 	errChan := make(chan error, 1)
 
 	go func() {
 		defer close(outChan)
 		defer close(errChan)
 
-		args := []string{
-			"simctl", "spawn", l.device.UDID,
-			"log", "stream",
-			"--style", "compact",
-			"--predicate", `processImagePath CONTAINS "` + l.bundleID + `"`,
-		}
+		var lines <-chan process.OutputLine
+		var errs <-chan error
 
-		lines, errs := l.runner.Run(ctx, "xcrun", args)
+		console := l.launch.Attached(l.device)
+		if console {
+			lines, errs = l.manager.StreamConsole(ctx, l.device, l.bundleID, l.launch)
+		} else {
+			args := append([]string{"simctl", "spawn", l.device.UDID}, logStreamArgs(l.bundleID, l.filter)...)
+			lines, errs = l.runner.Run(ctx, "xcrun", args)
+		}
 
 		for {
 			select {
@@ -46,8 +240,21 @@ func (l *LogStreamer) Stream(ctx context.Context) (<-chan string, <-chan error)
 			case line, ok := <-lines:
 				if !ok {
 					lines = nil
-				} else {
-					outChan <- line.Content
+					break
+				}
+
+				entry := LogEntry{Timestamp: time.Now(), Message: line.Content}
+				if !console {
+					if entry, ok = parseLogEntry(line.Content); !ok {
+						break
+					}
+				}
+				if l.filter.Match(entry) {
+					select {
+					case outChan <- entry:
+					case <-ctx.Done():
+						return
+					}
 				}
 			case err, ok := <-errs:
 				if ok && err != nil {
@@ -4,48 +4,132 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/arnavsurve/swiftctl/internal/cache"
 	"github.com/arnavsurve/swiftctl/internal/device"
+	"github.com/arnavsurve/swiftctl/internal/plist"
 )
 
-// FindApp locates the .app bundle in DerivedData after a build.
-func FindApp(projectName, scheme, configuration string, platform device.Platform) (string, error) {
-	home, err := os.UserHomeDir()
+// AppInfo is the metadata read from a built app's Info.plist.
+type AppInfo struct {
+	Path             string `json:"path"`
+	BundleID         string `json:"bundle_id"`
+	Executable       string `json:"executable"`
+	Version          string `json:"version"`
+	MinimumOSVersion string `json:"minimum_os_version"`
+	DeviceFamilies   []int  `json:"device_families,omitempty"`
+}
+
+// ReadAppInfo reads the Info.plist of the .app bundle at appPath. macOS
+// bundles keep it under Contents/.
+func ReadAppInfo(appPath string) (*AppInfo, error) {
+	plistPath := filepath.Join(appPath, "Info.plist")
+	if _, err := os.Stat(plistPath); err != nil {
+		plistPath = filepath.Join(appPath, "Contents", "Info.plist")
+	}
+
+	dict, err := plist.ReadDict(plistPath)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	derivedData := filepath.Join(home, "Library", "Developer", "Xcode", "DerivedData")
+	info := &AppInfo{
+		Path:             appPath,
+		BundleID:         plistString(dict, "CFBundleIdentifier"),
+		Executable:       plistString(dict, "CFBundleExecutable"),
+		Version:          plistString(dict, "CFBundleShortVersionString"),
+		MinimumOSVersion: plistString(dict, "MinimumOSVersion"),
+	}
+	if info.MinimumOSVersion == "" {
+		info.MinimumOSVersion = plistString(dict, "LSMinimumSystemVersion")
+	}
+	if info.BundleID == "" {
+		return nil, fmt.Errorf("%s: no CFBundleIdentifier", plistPath)
+	}
 
-	// Find project folder (has hash suffix)
-	pattern := filepath.Join(derivedData, projectName+"-*")
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		return "", err
+	families, _ := dict["UIDeviceFamily"].([]any)
+	for _, f := range families {
+		switch n := f.(type) {
+		case int64:
+			info.DeviceFamilies = append(info.DeviceFamilies, int(n))
+		case string: // occasionally written as strings by hand-edited plists
+			if i, err := strconv.Atoi(n); err == nil {
+				info.DeviceFamilies = append(info.DeviceFamilies, i)
+			}
+		}
 	}
 
-	if len(matches) == 0 {
-		return "", fmt.Errorf("no DerivedData found for %s", projectName)
+	return info, nil
+}
+
+// DeviceFamilyNames returns readable names for the UIDeviceFamily values.
+func (a *AppInfo) DeviceFamilyNames() []string {
+	var names []string
+	for _, f := range a.DeviceFamilies {
+		switch f {
+		case 1:
+			names = append(names, "iPhone")
+		case 2:
+			names = append(names, "iPad")
+		case 3:
+			names = append(names, "Apple TV")
+		case 4:
+			names = append(names, "Apple Watch")
+		case 6:
+			names = append(names, "Mac")
+		case 7:
+			names = append(names, "Apple Vision")
+		default:
+			names = append(names, fmt.Sprintf("family %d", f))
+		}
 	}
+	return names
+}
 
-	// Use most recently modified project folder
-	var bestMatch string
-	var bestTime int64
-	for _, m := range matches {
-		info, err := os.Stat(m)
-		if err == nil && info.ModTime().Unix() > bestTime {
-			bestTime = info.ModTime().Unix()
-			bestMatch = m
+// Summary is a one-line description for run output.
+func (a *AppInfo) Summary() string {
+	parts := []string{a.BundleID}
+	if a.Version != "" {
+		parts = append(parts, a.Version)
+	}
+	if names := a.DeviceFamilyNames(); len(names) > 0 {
+		parts = append(parts, strings.Join(names, "/"))
+	}
+	if a.MinimumOSVersion != "" {
+		parts = append(parts, "min OS "+a.MinimumOSVersion)
+	}
+	return strings.Join(parts, " · ")
+}
+
+func plistString(dict map[string]any, key string) string {
+	s, _ := dict[key].(string)
+	return s
+}
+
+// FindApp locates the .app bundle after a build. derivedData is the
+// -derivedDataPath the build used; when empty the most recently modified
+// project folder in Xcode's shared DerivedData is searched.
+func FindApp(derivedData, projectName, scheme, configuration string, platform device.Platform, physical bool) (string, error) {
+	bestMatch := derivedData
+	if bestMatch == "" {
+		var err error
+		if bestMatch, err = sharedDerivedData(projectName); err != nil {
+			return "", err
 		}
 	}
 
 	// Build products path
-	sdk := platformToSDK(platform)
+	sdk := platformToSDK(platform, physical)
 	if configuration == "" {
 		configuration = "Debug"
 	}
 	productsDir := filepath.Join(bestMatch, "Build", "Products", configuration+"-"+sdk)
+	if platform == device.PlatformMacOS {
+		// macOS products have no SDK suffix.
+		productsDir = filepath.Join(bestMatch, "Build", "Products", configuration)
+	}
 
 	apps, err := filepath.Glob(filepath.Join(productsDir, "*.app"))
 	if err != nil {
@@ -67,7 +151,93 @@ func FindApp(projectName, scheme, configuration string, platform device.Platform
 	return apps[0], nil
 }
 
-func platformToSDK(p device.Platform) string {
+// sharedDerivedData returns the most recently modified folder for the
+// project in Xcode's shared DerivedData.
+func sharedDerivedData(projectName string) (string, error) {
+	root, err := cache.XcodeDerivedData()
+	if err != nil {
+		return "", err
+	}
+
+	// Find project folder (has hash suffix)
+	matches, err := filepath.Glob(filepath.Join(root, projectName+"-*"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(matches) == 0 {
+		return "", fmt.Errorf("no DerivedData found for %s", projectName)
+	}
+
+	// Use most recently modified project folder
+	var bestMatch string
+	var bestTime int64
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err == nil && info.ModTime().Unix() > bestTime {
+			bestTime = info.ModTime().Unix()
+			bestMatch = m
+		}
+	}
+	return bestMatch, nil
+}
+
+// LatestApp returns the most recently built .app for the project across all
+// configurations and platforms, preferring one named after scheme. An empty
+// derivedData searches Xcode's shared DerivedData.
+func LatestApp(derivedData, projectName, scheme string) (string, error) {
+	if derivedData == "" {
+		root, err := cache.XcodeDerivedData()
+		if err != nil {
+			return "", err
+		}
+		derivedData = filepath.Join(root, projectName+"-*")
+	}
+
+	pattern := filepath.Join(derivedData, "Build", "Products", "*", "*.app")
+	apps, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var best string
+	var bestTime int64
+	for _, app := range apps {
+		info, err := os.Stat(app)
+		if err != nil {
+			continue
+		}
+		t := info.ModTime().Unix()
+		if strings.TrimSuffix(filepath.Base(app), ".app") == scheme {
+			// Any build of the scheme wins over other products.
+			t += 1 << 40
+		}
+		if t > bestTime {
+			bestTime = t
+			best = app
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("no built app found for %s", projectName)
+	}
+	return best, nil
+}
+
+func platformToSDK(p device.Platform, physical bool) string {
+	if physical {
+		switch p {
+		case device.PlatformTVOS:
+			return "appletvos"
+		case device.PlatformWatchOS:
+			return "watchos"
+		case device.PlatformVisionOS:
+			return "xros"
+		default:
+			return "iphoneos"
+		}
+	}
+
 	switch p {
 	case device.PlatformIOS:
 		return "iphonesimulator"
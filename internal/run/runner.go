@@ -2,15 +2,18 @@ package run
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/arnavsurve/swiftctl/internal/build"
+	"github.com/arnavsurve/swiftctl/internal/crash"
 	"github.com/arnavsurve/swiftctl/internal/device"
 	"github.com/arnavsurve/swiftctl/internal/process"
 	"github.com/arnavsurve/swiftctl/internal/project"
+	"github.com/arnavsurve/swiftctl/internal/session"
 	"github.com/arnavsurve/swiftctl/internal/ui"
 	"github.com/arnavsurve/swiftctl/internal/watcher"
 )
@@ -18,10 +21,22 @@ import (
 type Config struct {
 	Scheme        string
 	Configuration build.Configuration
-	DeviceName    string
+	Devices       []string // names or UDIDs; several deploy to each of them
 	Platform      device.Platform
 	Watch         bool
-	LaunchArgs    []string
+	Launch        device.LaunchOptions
+	ExtraArgs     []string // passed through to xcodebuild
+	WatchPatterns []string // extensions (".swift") or file name globs; empty uses the watcher's defaults
+	DerivedData   string   // -derivedDataPath; empty uses Xcode's shared DerivedData
+	LogFilter     LogFilter
+	LogJSON       bool          // print log entries as JSON lines
+	SaveLogs      bool          // tee build output and app logs to a session log per device
+	Headless      bool          // boot simulators without opening Simulator.app
+	BootTimeout   time.Duration // how long to wait for a simulator to boot; 0 uses the default
+
+	RelaunchOnCrash bool // in watch mode, relaunch an app that crashed
+
+	SourceMap map[string]string // build-time source path -> local path, for debug
 }
 
 type Runner struct {
@@ -30,42 +45,72 @@ type Runner struct {
 	builder       *build.Builder
 	renderer      *ui.Renderer
 	procRunner    *process.Runner
+	procOpts      []process.Option
+	session       *session.Session // nil unless cfg.SaveLogs
 }
 
-func NewRunner(proj *project.ProjectInfo) *Runner {
+func NewRunner(proj *project.ProjectInfo, opts ...process.Option) *Runner {
 	return &Runner{
 		project:       proj,
-		deviceManager: device.NewManager(),
-		builder:       build.NewBuilder(proj),
+		deviceManager: device.NewManager(opts...),
+		builder:       build.NewBuilder(proj, opts...),
 		renderer:      ui.NewRenderer(),
-		procRunner:    process.NewRunner(),
+		procRunner:    process.NewRunner(opts...),
+		procOpts:      opts,
 	}
 }
 
 func (r *Runner) Run(ctx context.Context, cfg Config) error {
-	// Resolve device
-	dev, err := r.resolveDevice(ctx, cfg)
+	if cfg.Platform == device.PlatformMacOS || r.project.Type == project.ProjectTypeSPM {
+		return r.runHost(ctx, cfg)
+	}
+
+	// Resolve devices
+	devs, err := r.ResolveDevices(ctx, cfg)
 	if err != nil {
 		return err
 	}
-	r.renderer.Info("Device: %s (%s)", dev.Name, dev.OSVersion)
+	for _, dev := range devs {
+		r.renderer.Info("Device: %s (%s)", dev.Name, dev.OSVersion)
+		if err := cfg.Launch.Validate(dev); err != nil {
+			return err
+		}
+	}
+	targets := r.targets(devs)
+
+	if cfg.SaveLogs && !r.procRunner.DryRun() {
+		r.session = session.New(r.project.Root())
+		defer r.session.Close()
+		for _, t := range targets {
+			if t.log, err = r.session.Open(t.dev.Name, t.dev.UDID); err != nil {
+				return fmt.Errorf("session log: %w", err)
+			}
+		}
+		r.renderer.Dim("Saving logs to %s", r.session.Dir())
+	}
 
 	// Initial build cycle
-	appPath, bundleID, err := r.buildCycle(ctx, cfg, dev)
+	bundleID, deployed, err := r.buildCycle(ctx, cfg, targets)
 	if err != nil {
 		return err
 	}
 
+	if r.procRunner.DryRun() {
+		return nil
+	}
+
 	if cfg.Watch {
-		return r.runWithWatch(ctx, cfg, dev, appPath, bundleID)
+		return r.runWithWatch(ctx, cfg, targets, deployed, bundleID)
 	}
 
-	return r.streamLogs(ctx, dev, bundleID)
+	return r.streamLogs(ctx, cfg, deployed, bundleID)
 }
 
-func (r *Runner) resolveDevice(ctx context.Context, cfg Config) (*device.Device, error) {
-	if cfg.DeviceName != "" {
-		dev, err := r.deviceManager.Get(ctx, cfg.DeviceName)
+// ResolveDevice picks the first device named in cfg, or a suitable one for
+// the platform, preferring devices that are already booted.
+func (r *Runner) ResolveDevice(ctx context.Context, cfg Config) (*device.Device, error) {
+	if len(cfg.Devices) > 0 {
+		dev, err := r.deviceManager.Get(ctx, cfg.Devices[0])
 		if err != nil {
 			return nil, fmt.Errorf("device not found: %w", err)
 		}
@@ -92,21 +137,29 @@ func (r *Runner) resolveDevice(ctx context.Context, cfg Config) (*device.Device,
 	return devices[0], nil
 }
 
-// buildCycle performs build -> boot -> install -> launch
-func (r *Runner) buildCycle(ctx context.Context, cfg Config, dev *device.Device) (appPath, bundleID string, err error) {
-	scheme := cfg.Scheme
-	if scheme == "" && len(r.project.Schemes) > 0 {
-		scheme = r.project.Schemes[0]
+// scheme returns the scheme to build: the configured one, or the project's first.
+func (r *Runner) scheme(cfg Config) string {
+	if cfg.Scheme == "" && len(r.project.Schemes) > 0 {
+		return r.project.Schemes[0]
 	}
+	return cfg.Scheme
+}
 
-	// Build
+// build compiles scheme for destination, rendering progress and
+// diagnostics, and returns the successful result.
+func (r *Runner) build(ctx context.Context, cfg Config, scheme, destination string) (*build.Result, error) {
+	r.renderer.StartGroup("Build %s", scheme)
 	r.renderer.StartSpinner("Building %s...", scheme)
+	r.session.Mark("Build %s started", scheme)
 
 	buildCfg := build.Config{
 		Scheme:        scheme,
 		Configuration: cfg.Configuration,
 		Platform:      cfg.Platform,
-		Destination:   fmt.Sprintf("platform=iOS Simulator,id=%s", dev.UDID),
+		Destination:   destination,
+		DerivedData:   cfg.DerivedData,
+		ExtraArgs:     cfg.ExtraArgs,
+		Output:        r.session.Println,
 	}
 
 	events := make(chan build.Event, 100)
@@ -121,6 +174,9 @@ func (r *Runner) buildCycle(ctx context.Context, cfg Config, dev *device.Device)
 				r.renderer.StopSpinner(true)
 				r.renderer.StartSpinner("Compiling %s...", lastFile)
 			case build.EventError:
+				if r.renderer.CI() {
+					continue
+				}
 				r.renderer.StopSpinner(false)
 				r.renderer.Error("%s:%d: %s", filepath.Base(ev.File), ev.Line, ev.Message)
 				r.renderer.StartSpinner("Building...")
@@ -132,10 +188,21 @@ func (r *Runner) buildCycle(ctx context.Context, cfg Config, dev *device.Device)
 	result, buildErr := r.builder.Build(ctx, buildCfg, events)
 	close(events)
 	<-done
+	r.renderer.EndGroup()
+
+	if result != nil {
+		for _, e := range result.Errors {
+			r.renderer.Annotate("error", e.File, e.Line, e.Column, e.Message)
+		}
+		for _, e := range result.Warnings {
+			r.renderer.Annotate("warning", e.File, e.Line, e.Column, e.Message)
+		}
+	}
 
 	if buildErr != nil {
 		r.renderer.StopSpinner(false)
-		return "", "", fmt.Errorf("build failed: %w", buildErr)
+		r.session.Mark("Build failed: %v", buildErr)
+		return nil, fmt.Errorf("build failed: %w", buildErr)
 	}
 
 	if result == nil || !result.Success {
@@ -144,92 +211,265 @@ func (r *Runner) buildCycle(ctx context.Context, cfg Config, dev *device.Device)
 		if result != nil {
 			errCount = len(result.Errors)
 		}
-		return "", "", fmt.Errorf("build failed with %d errors", errCount)
+		r.session.Mark("Build failed with %d errors", errCount)
+		return nil, fmt.Errorf("build failed with %d errors", errCount)
 	}
 
 	r.renderer.StopSpinner(true)
 	r.renderer.Success("Built in %.1fs", result.Duration.Seconds())
+	r.session.Mark("Build succeeded in %.1fs", result.Duration.Seconds())
+	return result, nil
+}
 
-	// Find .app
-	config := string(cfg.Configuration)
-	if config == "" {
-		config = "Debug"
+// buildCycle performs build -> boot -> install -> launch, deploying to
+// every target, and returns the ones the app is running on.
+func (r *Runner) buildCycle(ctx context.Context, cfg Config, targets []*target) (bundleID string, deployed []*target, err error) {
+	appPath, bundleID, err := r.buildApp(ctx, cfg, targets[0].dev)
+	if err != nil {
+		return "", nil, err
 	}
-	appPath, err = FindApp(r.project.Name, scheme, config, cfg.Platform)
+
+	deployed, err = r.deployAll(ctx, cfg, targets, appPath, bundleID)
 	if err != nil {
-		return "", "", fmt.Errorf("app not found: %w", err)
+		return "", nil, err
 	}
+	return bundleID, deployed, nil
+}
+
+// buildApp builds the app for dev and returns its path and bundle ID.
+func (r *Runner) buildApp(ctx context.Context, cfg Config, dev *device.Device) (appPath, bundleID string, err error) {
+	scheme := r.scheme(cfg)
 
-	// Extract bundle ID
-	bundleID, err = r.extractBundleID(appPath)
+	result, err := r.build(ctx, cfg, scheme, dev.Destination())
 	if err != nil {
-		return "", "", fmt.Errorf("bundle ID extraction failed: %w", err)
+		return "", "", err
+	}
+
+	// Find .app: build settings know the exact product path; fall back to
+	// searching DerivedData when they couldn't be read.
+	appPath = result.ProductPath
+	if appPath == "" {
+		config := string(cfg.Configuration)
+		if config == "" {
+			config = "Debug"
+		}
+		appPath, err = FindApp(cfg.DerivedData, r.project.Name, scheme, config, cfg.Platform, dev.Type == device.DeviceTypePhysical)
+		if err != nil {
+			if !r.procRunner.DryRun() {
+				return "", "", fmt.Errorf("app not found: %w", err)
+			}
+			// The build was only planned, so the product may not exist yet.
+			appPath = "<" + scheme + ".app>"
+		}
 	}
 
+	// Read app metadata
+	app, err := ReadAppInfo(appPath)
+	if err != nil {
+		if !r.procRunner.DryRun() {
+			return "", "", fmt.Errorf("reading Info.plist failed: %w", err)
+		}
+		app = &AppInfo{Path: appPath, BundleID: result.BundleID}
+		if app.BundleID == "" {
+			app.BundleID = "<bundle-id>"
+		}
+	} else {
+		r.renderer.Dim("%s", app.Summary())
+	}
+	return appPath, app.BundleID, nil
+}
+
+// deploy boots t's device, installs the app and launches it.
+func (r *Runner) deploy(ctx context.Context, cfg Config, t *target, appPath, bundleID string) error {
+	if err := r.install(ctx, cfg, t, appPath); err != nil {
+		return err
+	}
+
+	// With the console attached (physical devices, --console-pty) the app
+	// is launched by the LogStreamer, which also replaces the running instance.
+	if cfg.Launch.Attached(t.dev) {
+		t.pid = 0
+		t.log.Mark("Installed %s; launching with console attached", bundleID)
+		return nil
+	}
+
+	return r.launch(ctx, cfg, t, bundleID)
+}
+
+// install boots t's device if needed, waiting until it is ready, and
+// installs the app on it.
+func (r *Runner) install(ctx context.Context, cfg Config, t *target, appPath string) error {
+	dev, out := t.dev, t.out
+
 	// Boot device
-	if dev.State != device.StateBooted {
-		r.renderer.StartSpinner("Booting %s...", dev.Name)
-		if err := r.deviceManager.Boot(ctx, dev); err != nil {
-			r.renderer.StopSpinner(false)
-			return "", "", fmt.Errorf("boot failed: %w", err)
+	if dev.Type == device.DeviceTypePhysical {
+		if err := r.deviceManager.Boot(ctx, dev, device.BootOptions{}); err != nil {
+			return err
+		}
+	} else if dev.State != device.StateBooted {
+		out.StartSpinner("Booting %s...", dev.Name)
+		opts := device.BootOptions{
+			Wait:     true,
+			Timeout:  cfg.BootTimeout,
+			Headless: cfg.Headless,
+			Progress: func(stage string) {
+				out.UpdateSpinner("Booting %s (waiting on %s)...", dev.Name, stage)
+			},
+		}
+		if err := r.deviceManager.Boot(ctx, dev, opts); err != nil {
+			out.StopSpinner(false)
+			return fmt.Errorf("boot failed: %w", err)
 		}
-		r.renderer.StopSpinner(true)
+		out.StopSpinner(true)
+		dev.State = device.StateBooted
 	}
 
 	// Install
-	r.renderer.StartSpinner("Installing...")
+	out.StartSpinner("Installing...")
 	if err := r.deviceManager.Install(ctx, dev, appPath); err != nil {
-		r.renderer.StopSpinner(false)
-		return "", "", fmt.Errorf("install failed: %w", err)
+		out.StopSpinner(false)
+		return fmt.Errorf("install failed: %w", err)
 	}
-	r.renderer.StopSpinner(true)
+	out.StopSpinner(true)
+	t.appPath = appPath
+	return nil
+}
+
+// launch replaces any running instance of the app on t's device and
+// records the new PID for crash monitoring.
+func (r *Runner) launch(ctx context.Context, cfg Config, t *target, bundleID string) error {
+	dev, out := t.dev, t.out
 
 	// Terminate existing instance
 	_ = r.deviceManager.Terminate(ctx, dev, bundleID)
 
 	// Launch
-	r.renderer.StartSpinner("Launching...")
-	pid, err := r.deviceManager.Launch(ctx, dev, bundleID, cfg.LaunchArgs)
+	out.StartSpinner("Launching...")
+	pid, err := r.deviceManager.Launch(ctx, dev, bundleID, cfg.Launch)
 	if err != nil {
-		r.renderer.StopSpinner(false)
-		return "", "", fmt.Errorf("launch failed: %w", err)
+		out.StopSpinner(false)
+		return fmt.Errorf("launch failed: %w", err)
+	}
+	out.StopSpinner(true)
+	out.Success("Launched (PID %d)", pid)
+	t.log.Mark("Launched %s (PID %d)", bundleID, pid)
+	t.pid, t.launched = pid, time.Now()
+	if dev.Type == device.DeviceTypePhysical || cfg.Launch.WaitForDebugger {
+		// No PID polling on devices; a suspended app isn't worth watching.
+		t.pid = 0
+	}
+	if cfg.Launch.WaitForDebugger {
+		out.Info("Waiting for a debugger to attach to PID %d", pid)
+	}
+	for _, f := range []string{cfg.Launch.Stdout, cfg.Launch.Stderr} {
+		if f != "" {
+			out.Dim("Writing app output to %s", f)
+		}
 	}
-	r.renderer.StopSpinner(true)
-	r.renderer.Success("Launched (PID %d)", pid)
 
-	return appPath, bundleID, nil
+	return nil
 }
 
-func (r *Runner) streamLogs(ctx context.Context, dev *device.Device, bundleID string) error {
+func (r *Runner) streamLogs(ctx context.Context, cfg Config, targets []*target, bundleID string) error {
 	r.renderer.Dim("Streaming logs (Ctrl+C to stop)...")
 
-	streamer := NewLogStreamer(dev, bundleID)
-	logs, errs := streamer.Stream(ctx)
+	if len(targets) == 1 {
+		return r.followLogs(ctx, cfg, targets[0], bundleID)
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		case line, ok := <-logs:
-			if !ok {
-				return nil
+	// One device's stream failing leaves the others running.
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		crashed int
+	)
+	for _, t := range targets {
+		wg.Go(func() {
+			err := r.followLogs(ctx, cfg, t, bundleID)
+			switch {
+			case errors.Is(err, errCrashed):
+				mu.Lock()
+				crashed++
+				mu.Unlock()
+			case err != nil:
+				t.out.Error("Log stream failed: %v", err)
 			}
-			fmt.Println(line)
-		case err := <-errs:
-			if err != nil {
-				return err
+		})
+	}
+	wg.Wait()
+
+	if crashed > 0 {
+		return fmt.Errorf("app crashed on %d of %d devices", crashed, len(targets))
+	}
+	return nil
+}
+
+// followLogs prints t's log stream, prefixed, until it ends, the app exits
+// or ctx is done. It returns errCrashed after reporting a crash.
+func (r *Runner) followLogs(ctx context.Context, cfg Config, t *target, bundleID string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	exitErr := make(chan error, 1)
+	if t.pid > 0 {
+		go func() {
+			exited, rep := r.awaitExit(ctx, t.dev, t.pid, t.launched, bundleID)
+			if !exited {
+				return
 			}
-		}
+			r.reportAppExit(ctx, t, rep)
+			if rep != nil {
+				exitErr <- errCrashed
+			} else {
+				exitErr <- nil
+			}
+			cancel()
+		}()
+	}
+
+	streamer := NewLogStreamer(t.dev, bundleID, r.procOpts...)
+	streamer.SetLaunch(cfg.Launch)
+	streamer.SetFilter(cfg.LogFilter)
+	logs, errs := streamer.Stream(ctx)
+
+	for entry := range logs {
+		r.writeLog(cfg, t, entry)
+	}
+
+	select {
+	case err := <-exitErr:
+		return err
+	default:
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return <-errs
+}
+
+// writeLog prints an entry from t, naming the device in JSON output when
+// there are several.
+func (r *Runner) writeLog(cfg Config, t *target, entry LogEntry) {
+	if t.prefix != "" {
+		entry.Device = t.dev.Name
 	}
+	WriteLog(r.renderer, t.prefix, entry, cfg.LogJSON)
+	t.log.Println(entry.Timestamp, entry.String())
 }
 
-func (r *Runner) runWithWatch(ctx context.Context, cfg Config, dev *device.Device, appPath, bundleID string) error {
+// runWithWatch redeploys to every target after each successful rebuild.
+// live are the targets the app is currently running on.
+func (r *Runner) runWithWatch(ctx context.Context, cfg Config, targets, live []*target, bundleID string) error {
 	w, err := watcher.New(750 * time.Millisecond)
 	if err != nil {
 		return fmt.Errorf("watcher failed: %w", err)
 	}
 	defer w.Close()
 
+	if len(cfg.WatchPatterns) > 0 {
+		w.SetPatterns(cfg.WatchPatterns)
+	}
+
 	if err := w.AddRecursive("."); err != nil {
 		return fmt.Errorf("watch directory failed: %w", err)
 	}
@@ -246,20 +486,49 @@ func (r *Runner) runWithWatch(ctx context.Context, cfg Config, dev *device.Devic
 	}
 	defer cleanup()
 
-	startLogs := func(bid string) {
-		cleanup()
-		var logCtx context.Context
-		logCtx, currentCancel = context.WithCancel(ctx)
-		streamer := NewLogStreamer(dev, bid)
-		logs, _ := streamer.Stream(logCtx)
+	// Crash monitors report here while logs are streaming.
+	type appExit struct {
+		t   *target
+		rep *crash.Report
+	}
+	exits := make(chan appExit, len(targets))
+	var logCtx context.Context
 
+	monitor := func(t *target, bid string) {
+		if t.pid == 0 {
+			return
+		}
+		monitorCtx, pid, launched := logCtx, t.pid, t.launched
 		go func() {
-			for line := range logs {
-				fmt.Println(line)
+			exited, rep := r.awaitExit(monitorCtx, t.dev, pid, launched, bid)
+			if !exited {
+				return
+			}
+			select {
+			case exits <- appExit{t, rep}:
+			case <-monitorCtx.Done():
 			}
 		}()
 	}
 
+	startLogs := func(bid string) {
+		cleanup()
+		logCtx, currentCancel = context.WithCancel(ctx)
+		for _, t := range live {
+			monitor(t, bid)
+			streamer := NewLogStreamer(t.dev, bid, r.procOpts...)
+			streamer.SetLaunch(cfg.Launch)
+			streamer.SetFilter(cfg.LogFilter)
+			logs, _ := streamer.Stream(logCtx)
+
+			go func() {
+				for entry := range logs {
+					r.writeLog(cfg, t, entry)
+				}
+			}()
+		}
+	}
+
 	startLogs(bundleID)
 	r.renderer.Dim("Watching for changes (Ctrl+C to stop)...")
 
@@ -268,26 +537,56 @@ func (r *Runner) runWithWatch(ctx context.Context, cfg Config, dev *device.Devic
 		case <-ctx.Done():
 			return nil
 
+		case exit := <-exits:
+			r.reportAppExit(ctx, exit.t, exit.rep)
+			switch {
+			case exit.rep == nil:
+				exit.t.out.Dim("Waiting for changes to relaunch...")
+			case !cfg.RelaunchOnCrash:
+				exit.t.out.Dim("Waiting for changes to relaunch (or use --relaunch-on-crash)...")
+			case time.Since(exit.t.launched) < minUptime:
+				exit.t.out.Warning("Crashed within %s of launch; not relaunching until the next change", minUptime)
+			default:
+				if err := r.launch(ctx, cfg, exit.t, bundleID); err != nil {
+					exit.t.out.Error("Relaunch failed: %v", err)
+					continue
+				}
+				monitor(exit.t, bundleID)
+			}
+
 		case change, ok := <-changes:
 			if !ok {
 				return nil
 			}
 
 			r.renderer.Info("Changed: %s", filepath.Base(change.Path))
+			r.session.Mark("Changed %s; rebuilding", change.Path)
 
 			// Stop log streaming (app keeps running until build succeeds)
 			cleanup()
 
-			// Rebuild (buildCycle will terminate old app after successful build)
-			newAppPath, newBundleID, err := r.buildCycle(ctx, cfg, dev)
+			// The rebuild relaunches everything, so earlier exits are moot.
+		drainExits:
+			for {
+				select {
+				case <-exits:
+				default:
+					break drainExits
+				}
+			}
+
+			// Rebuild (buildCycle will terminate old app after successful
+			// build), redeploying to devices that failed last time too
+			newBundleID, deployed, err := r.buildCycle(ctx, cfg, targets)
 			if err != nil {
 				r.renderer.Error("Rebuild failed: %v", err)
+				r.session.Mark("Rebuild failed; previous build still running")
 				startLogs(bundleID)
 				continue
 			}
 
-			appPath = newAppPath
 			bundleID = newBundleID
+			live = deployed
 
 			// Drain any queued events (from atomic saves generating multiple events)
 			drainDone := time.After(100 * time.Millisecond)
@@ -306,13 +605,3 @@ func (r *Runner) runWithWatch(ctx context.Context, cfg Config, dev *device.Devic
 		}
 	}
 }
-
-func (r *Runner) extractBundleID(appPath string) (string, error) {
-	plistPath := filepath.Join(appPath, "Info.plist")
-	output, err := r.procRunner.RunSilent(context.Background(), "/usr/libexec/PlistBuddy",
-		[]string{"-c", "Print :CFBundleIdentifier", plistPath})
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
-}
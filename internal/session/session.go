@@ -0,0 +1,276 @@
+// Package session saves a run's build output and app logs to files under
+// .swiftctl/logs, one per device, so they outlive the terminal.
+//
+// Files are named <timestamp>-<device>-<udid>.log, with the first eight
+// characters of the UDID so same-named devices get their own file. When
+// one grows past MaxSize it is rotated to .log.1 (older parts shift to .2,
+// .3, ...) and at most MaxBackups rotated parts are kept.
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MaxSize    = 10 << 20 // bytes per file before rotating
+	MaxBackups = 5
+
+	stampFormat = "20060102-150405"
+)
+
+// Dir is where session logs for the project in root are kept.
+func Dir(root string) string {
+	return filepath.Join(root, ".swiftctl", "logs")
+}
+
+// Session is one run's set of log files.
+type Session struct {
+	dir   string
+	stamp string
+
+	mu   sync.Mutex
+	logs []*Log
+}
+
+// New starts a session for the project in root. Files are created as
+// devices are opened.
+func New(root string) *Session {
+	return &Session{dir: Dir(root), stamp: time.Now().Format(stampFormat)}
+}
+
+// Dir returns the directory the session writes to.
+func (s *Session) Dir() string {
+	return s.dir
+}
+
+// Open creates the log file for a device. udid may be empty when the name
+// alone is unique, such as for this Mac.
+func (s *Session) Open(device, udid string) (*Log, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	name := fileName(device)
+	if udid != "" {
+		name += "-" + fileName(udid[:min(len(udid), 8)])
+	}
+	l := &Log{path: filepath.Join(s.dir, s.stamp+"-"+name+".log")}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.logs = append(s.logs, l)
+	s.mu.Unlock()
+	return l, nil
+}
+
+// Println writes a line to every log in the session. It does nothing on a
+// nil Session.
+func (s *Session) Println(line string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.logs {
+		l.Println(time.Now(), line)
+	}
+}
+
+// Mark writes a marker line (build started, relaunched, ...) to every log
+// in the session.
+func (s *Session) Mark(format string, args ...any) {
+	s.Println(marker(format, args...))
+}
+
+// Close closes every log in the session.
+func (s *Session) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var first error
+	for _, l := range s.logs {
+		if err := l.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Log is one device's session log file. Its methods do nothing on a nil
+// Log, so callers don't need to check whether logs are being saved.
+type Log struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	size int64
+}
+
+// Path returns the current (newest) file.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Println writes line stamped with t.
+func (l *Log) Println(t time.Time, line string) {
+	if l == nil {
+		return
+	}
+	_, _ = l.Write([]byte(t.Format("2006-01-02 15:04:05.000") + " " + line + "\n"))
+}
+
+// Mark writes a marker line that stands out from app logs.
+func (l *Log) Mark(format string, args ...any) {
+	l.Println(time.Now(), marker(format, args...))
+}
+
+func marker(format string, args ...any) string {
+	return "=== " + fmt.Sprintf(format, args...) + " ==="
+}
+
+// Write appends p as is, rotating first if it would grow the file past
+// MaxSize.
+func (l *Log) Write(p []byte) (int, error) {
+	if l == nil {
+		return len(p), nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return 0, os.ErrClosed
+	}
+	if l.size > 0 && l.size+int64(len(p)) > MaxSize {
+		if err := l.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := l.f.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, and starts a new
+// file at path.
+func (l *Log) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+
+	_ = os.Remove(l.path + "." + strconv.Itoa(MaxBackups))
+	for i := MaxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(l.path+"."+strconv.Itoa(i), l.path+"."+strconv.Itoa(i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		return err
+	}
+	return l.open()
+}
+
+// Latest returns the log files of the most recent session in root, one per
+// device, sorted by name.
+func Latest(root string) ([]string, error) {
+	dir := Dir(root)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+
+	var latest string
+	for _, p := range paths {
+		if stamp := stampOf(p); stamp > latest {
+			latest = stamp
+		}
+	}
+	if latest == "" {
+		return nil, fmt.Errorf("no saved logs in %s (run with --save-logs)", dir)
+	}
+
+	var files []string
+	for _, p := range paths {
+		if stampOf(p) == latest {
+			files = append(files, p)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Parts returns a log's files oldest first: its rotated parts, then path.
+func Parts(path string) []string {
+	var parts []string
+	for i := MaxBackups; i >= 1; i-- {
+		p := path + "." + strconv.Itoa(i)
+		if _, err := os.Stat(p); err == nil {
+			parts = append(parts, p)
+		}
+	}
+	return append(parts, path)
+}
+
+func stampOf(path string) string {
+	base := filepath.Base(path)
+	if len(base) < len(stampFormat) {
+		return ""
+	}
+	stamp := base[:len(stampFormat)]
+	if _, err := time.Parse(stampFormat, stamp); err != nil {
+		return ""
+	}
+	return stamp
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName makes a device name safe to use in a file name.
+func fileName(device string) string {
+	name := strings.Trim(unsafeChars.ReplaceAllString(device, "-"), "-")
+	if name == "" {
+		return "device"
+	}
+	return name
+}
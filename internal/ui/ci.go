@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type CIProvider int
+
+const (
+	CINone CIProvider = iota
+	CIGeneric
+	CIGitHub
+	CIGitLab
+)
+
+var globalCI CIProvider
+
+// SetGlobalCI sets the CI mode for renderers created afterwards.
+func SetGlobalCI(p CIProvider) {
+	globalCI = p
+}
+
+// DetectCI reports which CI system, if any, swiftctl is running under.
+func DetectCI() CIProvider {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return CIGitHub
+	case os.Getenv("GITLAB_CI") != "":
+		return CIGitLab
+	default:
+		return CINone
+	}
+}
+
+// CI reports whether the renderer is in CI mode (no spinner, annotations on).
+func (r *Renderer) CI() bool {
+	return r.ci != CINone
+}
+
+// StartGroup opens a collapsible log section. Like the rest of the
+// renderer's output, CI markers go to stderr, leaving stdout to JSON and
+// other output meant for scripts.
+func (r *Renderer) StartGroup(format string, args ...any) {
+	title := fmt.Sprintf(format, args...)
+
+	switch r.ci {
+	case CIGitHub:
+		fmt.Fprintf(os.Stderr, "::group::%s\n", title)
+	case CIGitLab:
+		r.section = sectionName(title)
+		fmt.Fprintf(os.Stderr, "\033[0Ksection_start:%d:%s[collapsed=true]\r\033[0K%s\n", time.Now().Unix(), r.section, title)
+	case CIGeneric:
+		fmt.Fprintf(os.Stderr, "--- %s\n", title)
+	}
+}
+
+// EndGroup closes the section opened by StartGroup.
+func (r *Renderer) EndGroup() {
+	switch r.ci {
+	case CIGitHub:
+		fmt.Fprintln(os.Stderr, "::endgroup::")
+	case CIGitLab:
+		if r.section != "" {
+			fmt.Fprintf(os.Stderr, "\033[0Ksection_end:%d:%s\r\033[0K\n", time.Now().Unix(), r.section)
+			r.section = ""
+		}
+	}
+}
+
+// Annotate reports a diagnostic so the CI system can show it inline.
+// level is "error" or "warning". It does nothing outside CI mode.
+func (r *Renderer) Annotate(level, file string, line, col int, msg string) {
+	switch r.ci {
+	case CIGitHub:
+		var props []string
+		if file != "" {
+			// Annotations only attach to paths relative to the checkout.
+			if ws := os.Getenv("GITHUB_WORKSPACE"); ws != "" && filepath.IsAbs(file) {
+				if rel, err := filepath.Rel(ws, file); err == nil && !strings.HasPrefix(rel, "..") {
+					file = rel
+				}
+			}
+			props = append(props, "file="+escapeProperty(file))
+			if line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", line))
+			}
+			if col > 0 {
+				props = append(props, fmt.Sprintf("col=%d", col))
+			}
+		}
+		if len(props) > 0 {
+			fmt.Fprintf(os.Stderr, "::%s %s::%s\n", level, strings.Join(props, ","), escapeData(msg))
+		} else {
+			fmt.Fprintf(os.Stderr, "::%s::%s\n", level, escapeData(msg))
+		}
+	case CIGitLab, CIGeneric:
+		if file == "" {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", level, msg)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s: %s\n", file, line, col, level, msg)
+		}
+	}
+}
+
+// WriteSummary appends markdown to the job summary where the CI system
+// supports one (GitHub's $GITHUB_STEP_SUMMARY).
+func (r *Renderer) WriteSummary(markdown string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if r.ci != CIGitHub || path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(markdown); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+var nonSection = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func sectionName(title string) string {
+	return strings.Trim(nonSection.ReplaceAllString(strings.ToLower(title), "_"), "_")
+}
@@ -14,10 +14,14 @@ type Renderer struct {
 	mu          sync.Mutex
 	spinning    bool
 	spinnerDone chan struct{}
+	spinnerMsg  string
+	ci          CIProvider
+	section     string
+	prefix      string
 }
 
 func NewRenderer() *Renderer {
-	return &Renderer{}
+	return &Renderer{ci: globalCI}
 }
 
 var (
@@ -35,14 +39,21 @@ func (r *Renderer) StartSpinner(format string, args ...any) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.spinning {
+	// Spinner frames are noise in CI logs.
+	if r.spinning || r.ci != CINone {
+		return
+	}
+
+	// Prefixed renderers share the terminal with others, so a status line
+	// stands in for the spinner.
+	if r.prefix != "" {
+		fmt.Fprintf(os.Stderr, "%s  %s\n", r.prefix, dim(fmt.Sprintf(format, args...)))
 		return
 	}
 
 	r.spinning = true
 	r.spinnerDone = make(chan struct{})
-
-	msg := fmt.Sprintf(format, args...)
+	r.spinnerMsg = fmt.Sprintf(format, args...)
 
 	go func() {
 		frame := 0
@@ -55,7 +66,7 @@ func (r *Renderer) StartSpinner(format string, args ...any) {
 				return
 			case <-ticker.C:
 				r.mu.Lock()
-				fmt.Fprintf(os.Stderr, "\r%s %s", cyan(spinnerFrames[frame]), msg)
+				fmt.Fprintf(os.Stderr, "\r\033[K%s %s", cyan(spinnerFrames[frame]), r.spinnerMsg)
 				r.mu.Unlock()
 				frame = (frame + 1) % len(spinnerFrames)
 			}
@@ -63,6 +74,20 @@ func (r *Renderer) StartSpinner(format string, args ...any) {
 	}()
 }
 
+// UpdateSpinner replaces the running spinner's message, e.g. with the
+// current stage of a long operation. Without a spinner (CI, prefixed
+// output) the message is printed as a status line instead.
+func (r *Renderer) UpdateSpinner(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.spinning {
+		r.spinnerMsg = fmt.Sprintf(format, args...)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s  %s\n", r.prefix, dim(fmt.Sprintf(format, args...)))
+}
+
 func (r *Renderer) StopSpinner(success bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -78,23 +103,103 @@ func (r *Renderer) StopSpinner(success bool) {
 }
 
 func (r *Renderer) Success(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "%s %s\n", green("✓"), fmt.Sprintf(format, args...))
+	fmt.Fprintf(os.Stderr, "%s%s %s\n", r.prefix, green("✓"), fmt.Sprintf(format, args...))
 }
 
 func (r *Renderer) Error(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "%s %s\n", red("✗"), fmt.Sprintf(format, args...))
+	fmt.Fprintf(os.Stderr, "%s%s %s\n", r.prefix, red("✗"), fmt.Sprintf(format, args...))
 }
 
 func (r *Renderer) Warning(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "%s %s\n", yellow("!"), fmt.Sprintf(format, args...))
+	fmt.Fprintf(os.Stderr, "%s%s %s\n", r.prefix, yellow("!"), fmt.Sprintf(format, args...))
 }
 
 func (r *Renderer) Info(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "  %s\n", fmt.Sprintf(format, args...))
+	fmt.Fprintf(os.Stderr, "%s  %s\n", r.prefix, fmt.Sprintf(format, args...))
 }
 
 func (r *Renderer) Dim(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "  %s\n", dim(fmt.Sprintf(format, args...)))
+	fmt.Fprintf(os.Stderr, "%s  %s\n", r.prefix, dim(fmt.Sprintf(format, args...)))
+}
+
+// WithPrefix returns a renderer that starts every line with prefix (see
+// Prefix) and prints spinner messages as plain status lines, for output
+// that is interleaved with other renderers'.
+func (r *Renderer) WithPrefix(prefix string) *Renderer {
+	return &Renderer{ci: r.ci, prefix: prefix}
+}
+
+var prefixColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgGreen),
+	color.New(color.FgBlue),
+	color.New(color.FgHiRed),
+}
+
+// Prefix formats label as a colored "[label] " line prefix, padded to
+// width. i picks the color so neighbouring labels stay distinguishable.
+func Prefix(label string, i, width int) string {
+	c := prefixColors[i%len(prefixColors)]
+	return c.Sprintf("%-*s", width+2, "["+label+"]") + " "
+}
+
+var (
+	magenta = color.New(color.FgMagenta).SprintFunc()
+	boldRed = color.New(color.FgRed, color.Bold).SprintFunc()
+)
+
+// Log prints one log message to stdout as "time level [scope] message",
+// coloring the level. prefix is prepended as by WithPrefix; an empty level
+// (raw console output) prints just the message.
+func (r *Renderer) Log(prefix string, t time.Time, level, scope, message string) {
+	if level == "" {
+		fmt.Fprintf(os.Stdout, "%s%s\n", prefix, message)
+		return
+	}
+
+	tag := LevelTag(level)
+	switch level {
+	case "fault":
+		tag = boldRed(tag)
+	case "error":
+		tag = red(tag)
+	case "info":
+		tag = cyan(tag)
+	case "debug":
+		tag = magenta(tag)
+	}
+
+	if scope != "" {
+		scope = dim("["+scope+"]") + " "
+	}
+	fmt.Fprintf(os.Stdout, "%s%s %s %s%s\n", prefix, dim(t.Format("15:04:05.000")), tag, scope, message)
+}
+
+// LevelTag is the three-letter tag Log shows for a log level, blank for
+// the default level.
+func LevelTag(level string) string {
+	switch level {
+	case "fault":
+		return "FLT"
+	case "error":
+		return "ERR"
+	case "info":
+		return "INF"
+	case "debug":
+		return "DBG"
+	default:
+		return "   "
+	}
+}
+
+// Duration formats short timings like test durations, e.g. "0.012s".
+func Duration(d time.Duration) string {
+	if d >= 10*time.Second {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return fmt.Sprintf("%.3fs", d.Seconds())
 }
 
 type DeviceInfo struct {
@@ -120,7 +225,7 @@ func (r *Renderer) RenderDeviceList(devices []DeviceInfo) {
 		fmt.Fprintf(os.Stderr, "\n%s\n", bold(strings.ToUpper(platform)))
 		for _, d := range devs {
 			stateColor := dim
-			if d.State == "Booted" {
+			if d.State == "Booted" || d.State == "Connected" {
 				stateColor = green
 			}
 			fmt.Fprintf(os.Stderr, "  %s %s %s\n",
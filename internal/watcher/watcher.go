@@ -35,6 +35,13 @@ func New(debounce time.Duration) (*Watcher, error) {
 	}, nil
 }
 
+// SetPatterns replaces the files that trigger changes. Patterns starting
+// with "." are extensions; anything else is matched against the file name
+// with filepath.Match, e.g. "*.json" or "Info.plist".
+func (w *Watcher) SetPatterns(patterns []string) {
+	w.patterns = patterns
+}
+
 // AddRecursive adds a directory and all subdirectories.
 func (w *Watcher) AddRecursive(root string) error {
 	absRoot, err := filepath.Abs(root)
@@ -135,8 +142,13 @@ func (w *Watcher) Watch(ctx context.Context) <-chan ChangeEvent {
 
 func (w *Watcher) shouldWatch(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
+	base := filepath.Base(path)
 	for _, pattern := range w.patterns {
-		if ext == pattern {
+		if strings.HasPrefix(pattern, ".") && !strings.ContainsAny(pattern, "*?[") {
+			if ext == strings.ToLower(pattern) {
+				return true
+			}
+		} else if ok, _ := filepath.Match(pattern, base); ok {
 			return true
 		}
 	}